@@ -10,10 +10,13 @@ import (
 type ArrayLiteral struct {
 	Token    token.Token
 	Elements []Expression
+	EndToken token.Token
 }
 
 func (al *ArrayLiteral) expressionNode()      {}
 func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() token.Position  { return al.Token.Start }
+func (al *ArrayLiteral) End() token.Position  { return al.EndToken.End }
 func (al *ArrayLiteral) String() string {
 	var out bytes.Buffer
 
@@ -11,10 +11,13 @@ type CallExpression struct {
 	Token     token.Token
 	Function  Expression
 	Arguments []Expression
+	EndToken  token.Token
 }
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() token.Position  { return startOf(ce.Function, ce.Token) }
+func (ce *CallExpression) End() token.Position  { return ce.EndToken.End }
 
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
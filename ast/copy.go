@@ -0,0 +1,201 @@
+package ast
+
+func Copy(node Node) Node {
+	switch node := node.(type) {
+	case *Program:
+		c := *node
+		c.Statements = copyStatements(node.Statements)
+		return &c
+	case *ExpressionStatement:
+		c := *node
+		c.Expression = copyExpression(node.Expression)
+		return &c
+	case *BlockStatement:
+		return copyBlock(node)
+	case *LetStatement:
+		c := *node
+		c.Name = copyIdentifier(node.Name)
+		c.Unquote = copyExpression(node.Unquote)
+		c.Value = copyExpression(node.Value)
+		return &c
+	case *ReturnStatement:
+		c := *node
+		c.ReturnValue = copyExpression(node.ReturnValue)
+		return &c
+	case *BreakStatement:
+		c := *node
+		return &c
+	case *ContinueStatement:
+		c := *node
+		return &c
+	case *Identifier:
+		return copyIdentifier(node)
+	case *IntegerLiteral:
+		c := *node
+		return &c
+	case *FloatLiteral:
+		c := *node
+		return &c
+	case *StringLiteral:
+		c := *node
+		return &c
+	case *Boolean:
+		c := *node
+		return &c
+	case *Null:
+		c := *node
+		return &c
+	case *PrefixExpression:
+		c := *node
+		c.Right = copyExpression(node.Right)
+		return &c
+	case *InfixExpression:
+		c := *node
+		c.Left = copyExpression(node.Left)
+		c.Right = copyExpression(node.Right)
+		return &c
+	case *AssignExpression:
+		c := *node
+		c.Target = copyExpression(node.Target)
+		c.Value = copyExpression(node.Value)
+		return &c
+	case *CallExpression:
+		c := *node
+		c.Function = copyExpression(node.Function)
+		c.Arguments = copyExpressions(node.Arguments)
+		return &c
+	case *IndexExpression:
+		c := *node
+		c.Left = copyExpression(node.Left)
+		c.Index = copyExpression(node.Index)
+		return &c
+	case *SpreadExpression:
+		c := *node
+		c.Value = copyExpression(node.Value)
+		return &c
+	case *ThrowExpression:
+		c := *node
+		c.Value = copyExpression(node.Value)
+		return &c
+	case *IfExpression:
+		c := *node
+		c.Condition = copyExpression(node.Condition)
+		c.Consequence = copyBlock(node.Consequence)
+		c.Alternative = copyBlock(node.Alternative)
+		return &c
+	case *WhileExpression:
+		c := *node
+		c.Condition = copyExpression(node.Condition)
+		c.Body = copyBlock(node.Body)
+		return &c
+	case *ForExpression:
+		c := *node
+		c.Key = copyIdentifier(node.Key)
+		c.Value = copyIdentifier(node.Value)
+		c.Collection = copyExpression(node.Collection)
+		c.Body = copyBlock(node.Body)
+		return &c
+	case *TryExpression:
+		c := *node
+		c.Block = copyBlock(node.Block)
+		c.CatchParameter = copyIdentifier(node.CatchParameter)
+		c.Catch = copyBlock(node.Catch)
+		c.Finally = copyBlock(node.Finally)
+		return &c
+	case *FunctionLiteral:
+		c := *node
+		c.Parameters = copyIdentifiers(node.Parameters)
+		c.Defaults = copyExpressions(node.Defaults)
+		c.Rest = copyIdentifier(node.Rest)
+		c.Body = copyBlock(node.Body)
+		return &c
+	case *MacroLiteral:
+		c := *node
+		c.Parameters = copyIdentifiers(node.Parameters)
+		c.Rest = copyIdentifier(node.Rest)
+		c.Body = copyBlock(node.Body)
+		return &c
+	case *ArrayLiteral:
+		c := *node
+		c.Elements = copyExpressions(node.Elements)
+		return &c
+	case *HashLiteral:
+		c := *node
+		if node.Pairs != nil {
+			c.Pairs = make(map[Expression]Expression, len(node.Pairs))
+			for key, value := range node.Pairs {
+				c.Pairs[copyExpression(key)] = copyExpression(value)
+			}
+		}
+		return &c
+	}
+
+	return node
+}
+
+func copyExpression(exp Expression) Expression {
+	if exp == nil {
+		return nil
+	}
+
+	c, _ := Copy(exp).(Expression)
+	return c
+}
+
+func copyExpressions(exps []Expression) []Expression {
+	if exps == nil {
+		return nil
+	}
+
+	c := make([]Expression, len(exps))
+	for i, exp := range exps {
+		c[i] = copyExpression(exp)
+	}
+
+	return c
+}
+
+func copyStatements(stmts []Statement) []Statement {
+	if stmts == nil {
+		return nil
+	}
+
+	c := make([]Statement, len(stmts))
+	for i, stmt := range stmts {
+		c[i], _ = Copy(stmt).(Statement)
+	}
+
+	return c
+}
+
+func copyBlock(block *BlockStatement) *BlockStatement {
+	if block == nil {
+		return nil
+	}
+
+	c := *block
+	c.Statements = copyStatements(block.Statements)
+	return &c
+}
+
+func copyIdentifier(identifier *Identifier) *Identifier {
+	if identifier == nil {
+		return nil
+	}
+
+	c := *identifier
+	return &c
+}
+
+func copyIdentifiers(identifiers []*Identifier) []*Identifier {
+	if identifiers == nil {
+		return nil
+	}
+
+	c := make([]*Identifier, len(identifiers))
+	for i, identifier := range identifiers {
+		c[i] = copyIdentifier(identifier)
+	}
+
+	return c
+}
@@ -0,0 +1,56 @@
+package ast
+
+import "testing"
+
+func TestCopy(t *testing.T) {
+	original := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Name: &Identifier{Value: "f"},
+				Value: &FunctionLiteral{
+					Parameters: []*Identifier{{Value: "x"}},
+					Body: &BlockStatement{
+						Statements: []Statement{
+							&ExpressionStatement{Expression: &CallExpression{
+								Function:  &Identifier{Value: "g"},
+								Arguments: []Expression{one(), &ArrayLiteral{Elements: []Expression{one()}}},
+							}},
+						},
+					},
+				},
+			},
+			&ExpressionStatement{Expression: &IfExpression{
+				Condition:   one(),
+				Consequence: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: one()}}},
+			}},
+			&ExpressionStatement{Expression: &HashLiteral{Pairs: map[Expression]Expression{one(): one()}}},
+		},
+	}
+
+	copied := Copy(original)
+	if copied.String() != original.String() {
+		t.Fatalf("copy is not equal to the original. got=%q, want=%q", copied.String(), original.String())
+	}
+
+	Modify(copied, turnOneIntoTwo)
+
+	if got := countIntegers(original, 1); got != 6 {
+		t.Errorf("modifying the copy changed the original. want=6 ones, got=%d", got)
+	}
+
+	if got := countIntegers(copied, 2); got != 6 {
+		t.Errorf("copy was not modified. want=6 twos, got=%d", got)
+	}
+}
+
+func countIntegers(node Node, value int64) int {
+	count := 0
+	Modify(node, func(node Node) Node {
+		if integer, ok := node.(*IntegerLiteral); ok && integer.Value == value {
+			count++
+		}
+		return node
+	})
+
+	return count
+}
@@ -11,6 +11,8 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() token.Position  { return startOf(es.Expression, es.Token) }
+func (es *ExpressionStatement) End() token.Position  { return endOf(es.Expression, es.Token) }
 
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
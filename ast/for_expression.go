@@ -0,0 +1,43 @@
+package ast
+
+import (
+	"bytes"
+
+	"monkeylang/token"
+)
+
+type ForExpression struct {
+	Token      token.Token
+	Key        *Identifier
+	Value      *Identifier
+	Collection Expression
+	Body       *BlockStatement
+}
+
+func (fe *ForExpression) expressionNode()      {}
+func (fe *ForExpression) TokenLiteral() string { return fe.Token.Literal }
+func (fe *ForExpression) Pos() token.Position  { return fe.Token.Start }
+func (fe *ForExpression) End() token.Position {
+	if fe.Body == nil {
+		return fe.Token.End
+	}
+
+	return fe.Body.End()
+}
+
+func (fe *ForExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	if fe.Key != nil {
+		out.WriteString(fe.Key.String())
+		out.WriteString(", ")
+	}
+	out.WriteString(fe.Value.String())
+	out.WriteString(" in ")
+	out.WriteString(fe.Collection.String())
+	out.WriteString(") ")
+	out.WriteString(fe.Body.String())
+
+	return out.String()
+}
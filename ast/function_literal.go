@@ -9,19 +9,46 @@ import (
 
 type FunctionLiteral struct {
 	Token      token.Token
+	Name       string
 	Parameters []*Identifier
-	Body	   *BlockStatement
+	Defaults   []Expression
+	Rest       *Identifier
+	Body       *BlockStatement
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) Pos() token.Position  { return fl.Token.Start }
+func (fl *FunctionLiteral) End() token.Position {
+	if fl.Body == nil {
+		return fl.Token.End
+	}
+
+	return fl.Body.End()
+}
+
+func (fl *FunctionLiteral) Default(i int) Expression {
+	if i >= len(fl.Defaults) {
+		return nil
+	}
 
-func (fl *FunctionLiteral) String() string       {
+	return fl.Defaults[i]
+}
+
+func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
 	params := []string{}
-	for _, param := range fl.Parameters {
-		params = append(params, param.String())
+	for i, param := range fl.Parameters {
+		if def := fl.Default(i); def != nil {
+			params = append(params, param.String()+" = "+def.String())
+		} else {
+			params = append(params, param.String())
+		}
+	}
+
+	if fl.Rest != nil {
+		params = append(params, "..."+fl.Rest.String())
 	}
 
 	out.WriteString(fl.TokenLiteral())
@@ -8,12 +8,15 @@ import (
 )
 
 type HashLiteral struct {
-	Token token.Token
-	Pairs map[Expression]Expression
+	Token    token.Token
+	Pairs    map[Expression]Expression
+	EndToken token.Token
 }
 
 func (hl *HashLiteral) expressionNode()      {}
 func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) Pos() token.Position  { return hl.Token.Start }
+func (hl *HashLiteral) End() token.Position  { return hl.EndToken.End }
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 
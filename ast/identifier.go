@@ -9,4 +9,6 @@ type Identifier struct {
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
-func (i *Identifier) String() string { return i.Value }
+func (i *Identifier) Pos() token.Position  { return i.Token.Start }
+func (i *Identifier) End() token.Position  { return i.Token.End }
+func (i *Identifier) String() string       { return i.Value }
@@ -15,6 +15,18 @@ type IfExpression struct {
 
 func (ie *IfExpression) expressionNode()      {}
 func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) Pos() token.Position  { return ie.Token.Start }
+func (ie *IfExpression) End() token.Position {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+
+	if ie.Consequence != nil {
+		return ie.Consequence.End()
+	}
+
+	return ie.Token.End
+}
 
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
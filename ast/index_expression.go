@@ -7,13 +7,16 @@ import (
 )
 
 type IndexExpression struct {
-	Token token.Token
-	Left  Expression
-	Index Expression
+	Token    token.Token
+	Left     Expression
+	Index    Expression
+	EndToken token.Token
 }
 
 func (ie *IndexExpression) expressionNode()      {}
 func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Pos() token.Position  { return startOf(ie.Left, ie.Token) }
+func (ie *IndexExpression) End() token.Position  { return ie.EndToken.End }
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 
@@ -15,6 +15,8 @@ type InfixExpression struct {
 
 func (ie *InfixExpression) expressionNode()      {}
 func (ie *InfixExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *InfixExpression) Pos() token.Position  { return startOf(ie.Left, ie.Token) }
+func (ie *InfixExpression) End() token.Position  { return endOf(ie.Right, ie.Token) }
 
 func (ie *InfixExpression) String() string {
 	var out bytes.Buffer
@@ -7,19 +7,26 @@ import (
 )
 
 type LetStatement struct {
-	Token token.Token
-	Name  *Identifier
-	Value Expression
+	Token   token.Token
+	Name    *Identifier
+	Unquote Expression
+	Value   Expression
 }
 
 func (ls *LetStatement) statementNode()       {}
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LetStatement) Pos() token.Position  { return ls.Token.Start }
+func (ls *LetStatement) End() token.Position  { return endOf(ls.Value, ls.Token) }
 
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(ls.TokenLiteral() + " ")
-	out.WriteString(ls.Name.String())
+	if ls.Name != nil {
+		out.WriteString(ls.Name.String())
+	} else {
+		out.WriteString(ls.Unquote.String())
+	}
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
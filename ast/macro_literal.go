@@ -9,11 +9,21 @@ import (
 type MacroLiteral struct {
 	Token      token.Token
 	Parameters []*Identifier
+	Rest       *Identifier
 	Body       *BlockStatement
 }
 
 func (ml *MacroLiteral) expressionNode()      {}
 func (ml *MacroLiteral) TokenLiteral() string { return ml.Token.Literal }
+func (ml *MacroLiteral) Pos() token.Position  { return ml.Token.Start }
+func (ml *MacroLiteral) End() token.Position {
+	if ml.Body == nil {
+		return ml.Token.End
+	}
+
+	return ml.Body.End()
+}
+
 func (ml *MacroLiteral) String() string {
 	var out bytes.Buffer
 
@@ -23,6 +33,10 @@ func (ml *MacroLiteral) String() string {
 		params = append(params, p.String())
 	}
 
+	if ml.Rest != nil {
+		params = append(params, "..."+ml.Rest.String())
+	}
+
 	out.WriteString(ml.TokenLiteral())
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
@@ -13,6 +13,14 @@ func Modify(node Node, modifier ModifierFunc) Node {
 	case *InfixExpression:
 		node.Left, _ = Modify(node.Left, modifier).(Expression)
 		node.Right, _ = Modify(node.Right, modifier).(Expression)
+	case *AssignExpression:
+		node.Target, _ = Modify(node.Target, modifier).(Expression)
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *CallExpression:
+		node.Function, _ = Modify(node.Function, modifier).(Expression)
+		for i := range node.Arguments {
+			node.Arguments[i], _ = Modify(node.Arguments[i], modifier).(Expression)
+		}
 	case *PrefixExpression:
 		node.Right, _ = Modify(node.Right, modifier).(Expression)
 	case *IndexExpression:
@@ -24,6 +32,29 @@ func Modify(node Node, modifier ModifierFunc) Node {
 		if node.Alternative != nil {
 			node.Alternative, _ = Modify(node.Alternative, modifier).(*BlockStatement)
 		}
+	case *TryExpression:
+		node.Block, _ = Modify(node.Block, modifier).(*BlockStatement)
+		if node.Catch != nil {
+			node.CatchParameter, _ = Modify(node.CatchParameter, modifier).(*Identifier)
+			node.Catch, _ = Modify(node.Catch, modifier).(*BlockStatement)
+		}
+		if node.Finally != nil {
+			node.Finally, _ = Modify(node.Finally, modifier).(*BlockStatement)
+		}
+	case *WhileExpression:
+		node.Condition, _ = Modify(node.Condition, modifier).(Expression)
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+	case *ForExpression:
+		if node.Key != nil {
+			node.Key, _ = Modify(node.Key, modifier).(*Identifier)
+		}
+		node.Value, _ = Modify(node.Value, modifier).(*Identifier)
+		node.Collection, _ = Modify(node.Collection, modifier).(Expression)
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+	case *ThrowExpression:
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *SpreadExpression:
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
 	case *BlockStatement:
 		for i := range node.Statements {
 			node.Statements[i], _ = Modify(node.Statements[i], modifier).(Statement)
@@ -31,12 +62,29 @@ func Modify(node Node, modifier ModifierFunc) Node {
 	case *ReturnStatement:
 		node.ReturnValue, _ = Modify(node.ReturnValue, modifier).(Expression)
 	case *LetStatement:
+		if node.Unquote != nil {
+			node.Unquote, _ = Modify(node.Unquote, modifier).(Expression)
+			if name, ok := node.Unquote.(*Identifier); ok {
+				node.Name, node.Unquote = name, nil
+			}
+		}
+
 		node.Value, _ = Modify(node.Value, modifier).(Expression)
 	case *FunctionLiteral:
 		for i := range node.Parameters {
 			node.Parameters[i] = Modify(node.Parameters[i], modifier).(*Identifier)
 		}
 
+		for i := range node.Defaults {
+			if node.Defaults[i] != nil {
+				node.Defaults[i], _ = Modify(node.Defaults[i], modifier).(Expression)
+			}
+		}
+
+		if node.Rest != nil {
+			node.Rest, _ = Modify(node.Rest, modifier).(*Identifier)
+		}
+
 		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
 	case *ArrayLiteral:
 		for i := range node.Elements {
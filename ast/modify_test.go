@@ -85,6 +85,90 @@ func TestModify(t *testing.T) {
 				},
 			},
 		},
+		{
+			&TryExpression{
+				Block: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: one()},
+					},
+				},
+				CatchParameter: &Identifier{Value: "e"},
+				Catch: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: one()},
+					},
+				},
+				Finally: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: one()},
+					},
+				},
+			},
+			&TryExpression{
+				Block: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: two()},
+					},
+				},
+				CatchParameter: &Identifier{Value: "e"},
+				Catch: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: two()},
+					},
+				},
+				Finally: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: two()},
+					},
+				},
+			},
+		},
+		{
+			&WhileExpression{
+				Condition: one(),
+				Body: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: one()},
+					},
+				},
+			},
+			&WhileExpression{
+				Condition: two(),
+				Body: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: two()},
+					},
+				},
+			},
+		},
+		{
+			&ForExpression{
+				Value:      &Identifier{Value: "x"},
+				Collection: one(),
+				Body: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: one()},
+					},
+				},
+			},
+			&ForExpression{
+				Value:      &Identifier{Value: "x"},
+				Collection: two(),
+				Body: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: two()},
+					},
+				},
+			},
+		},
+		{
+			&AssignExpression{Target: &IndexExpression{Left: one(), Index: one()}, Operator: "+=", Value: one()},
+			&AssignExpression{Target: &IndexExpression{Left: two(), Index: two()}, Operator: "+=", Value: two()},
+		},
+		{
+			&ThrowExpression{Value: one()},
+			&ThrowExpression{Value: two()},
+		},
 		{
 			&ReturnStatement{ReturnValue: one()},
 			&ReturnStatement{ReturnValue: two()},
@@ -111,10 +195,30 @@ func TestModify(t *testing.T) {
 				},
 			},
 		},
+		{
+			&FunctionLiteral{
+				Parameters: []*Identifier{{Value: "a"}},
+				Defaults:   []Expression{one()},
+				Body:       &BlockStatement{Statements: []Statement{}},
+			},
+			&FunctionLiteral{
+				Parameters: []*Identifier{{Value: "a"}},
+				Defaults:   []Expression{two()},
+				Body:       &BlockStatement{Statements: []Statement{}},
+			},
+		},
 		{
 			&ArrayLiteral{Elements: []Expression{one(), two()}},
 			&ArrayLiteral{Elements: []Expression{two(), two()}},
 		},
+		{
+			&SpreadExpression{Value: one()},
+			&SpreadExpression{Value: two()},
+		},
+		{
+			&CallExpression{Function: one(), Arguments: []Expression{one(), two()}},
+			&CallExpression{Function: two(), Arguments: []Expression{two(), two()}},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1,6 +1,26 @@
 package ast
 
+import "monkeylang/token"
+
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position
+	End() token.Position
+}
+
+func startOf(node Node, fallback token.Token) token.Position {
+	if node == nil {
+		return fallback.Start
+	}
+
+	return node.Pos()
+}
+
+func endOf(node Node, fallback token.Token) token.Position {
+	if node == nil {
+		return fallback.End
+	}
+
+	return node.End()
 }
@@ -8,4 +8,6 @@ type Null struct {
 
 func (n *Null) expressionNode()      {}
 func (n *Null) TokenLiteral() string { return n.Token.Literal }
+func (n *Null) Pos() token.Position  { return n.Token.Start }
+func (n *Null) End() token.Position  { return n.Token.End }
 func (n *Null) String() string       { return n.Token.Literal }
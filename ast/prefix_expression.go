@@ -14,6 +14,8 @@ type PrefixExpression struct {
 
 func (pe *PrefixExpression) expressionNode()      {}
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) Pos() token.Position  { return pe.Token.Start }
+func (pe *PrefixExpression) End() token.Position  { return endOf(pe.Right, pe.Token) }
 
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
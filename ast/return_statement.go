@@ -13,6 +13,8 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() token.Position  { return rs.Token.Start }
+func (rs *ReturnStatement) End() token.Position  { return endOf(rs.ReturnValue, rs.Token) }
 
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
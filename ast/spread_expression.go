@@ -0,0 +1,26 @@
+package ast
+
+import (
+	"bytes"
+
+	"monkeylang/token"
+)
+
+type SpreadExpression struct {
+	Token token.Token
+	Value Expression
+}
+
+func (se *SpreadExpression) expressionNode()      {}
+func (se *SpreadExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpreadExpression) Pos() token.Position  { return se.Token.Start }
+func (se *SpreadExpression) End() token.Position  { return endOf(se.Value, se.Token) }
+
+func (se *SpreadExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("...")
+	out.WriteString(se.Value.String())
+
+	return out.String()
+}
@@ -9,4 +9,6 @@ type StringLiteral struct {
 
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) Pos() token.Position  { return sl.Token.Start }
+func (sl *StringLiteral) End() token.Position  { return sl.Token.End }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
@@ -0,0 +1,26 @@
+package ast
+
+import (
+	"bytes"
+
+	"monkeylang/token"
+)
+
+type ThrowExpression struct {
+	Token token.Token
+	Value Expression
+}
+
+func (te *ThrowExpression) expressionNode()      {}
+func (te *ThrowExpression) TokenLiteral() string { return te.Token.Literal }
+func (te *ThrowExpression) Pos() token.Position  { return te.Token.Start }
+func (te *ThrowExpression) End() token.Position  { return endOf(te.Value, te.Token) }
+
+func (te *ThrowExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("throw ")
+	out.WriteString(te.Value.String())
+
+	return out.String()
+}
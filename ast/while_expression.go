@@ -0,0 +1,35 @@
+package ast
+
+import (
+	"bytes"
+
+	"monkeylang/token"
+)
+
+type WhileExpression struct {
+	Token     token.Token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (we *WhileExpression) expressionNode()      {}
+func (we *WhileExpression) TokenLiteral() string { return we.Token.Literal }
+func (we *WhileExpression) Pos() token.Position  { return we.Token.Start }
+func (we *WhileExpression) End() token.Position {
+	if we.Body == nil {
+		return we.Token.End
+	}
+
+	return we.Body.End()
+}
+
+func (we *WhileExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while")
+	out.WriteString(we.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(we.Body.String())
+
+	return out.String()
+}
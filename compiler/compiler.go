@@ -0,0 +1,982 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"monkeylang/ast"
+	"monkeylang/code"
+	"monkeylang/object"
+	"monkeylang/token"
+)
+
+type Bytecode struct {
+	Instructions code.Instructions
+	Positions    map[int]token.Position
+	Constants    []object.Object
+	GlobalNames  []string
+	Builtins     *object.BuiltinRegistry
+}
+
+type EmittedInstruction struct {
+	Opcode   code.Opcode
+	Position int
+}
+
+type CompilationScope struct {
+	instructions        code.Instructions
+	positions           map[int]token.Position
+	lastInstruction     EmittedInstruction
+	previousInstruction EmittedInstruction
+	tryBlocks           []tryBlock
+	loops               []loop
+}
+
+type tryBlock struct {
+	finally *ast.BlockStatement
+}
+
+type loop struct {
+	start      int
+	tryDepth   int
+	breakJumps []int
+}
+
+type Compiler struct {
+	constants   []object.Object
+	symbolTable *SymbolTable
+
+	scopes     []CompilationScope
+	scopeIndex int
+
+	position token.Position
+	err      error
+}
+
+func New() *Compiler {
+	mainScope := CompilationScope{
+		instructions: code.Instructions{},
+		positions:    make(map[int]token.Position),
+	}
+
+	symbolTable := NewSymbolTableWithBuiltins(object.NewBuiltinRegistry())
+
+	return &Compiler{
+		constants:   []object.Object{},
+		symbolTable: symbolTable,
+		scopes:      []CompilationScope{mainScope},
+		scopeIndex:  0,
+	}
+}
+
+func NewWithState(s *SymbolTable, constants []object.Object) *Compiler {
+	compiler := New()
+	compiler.symbolTable = s
+	compiler.constants = constants
+	return compiler
+}
+
+func (c *Compiler) Compile(node ast.Node) error {
+	if node == nil {
+		return nil
+	}
+
+	position := c.position
+	c.position = node.Pos()
+	defer func() { c.position = position }()
+
+	err := c.compile(node)
+	if err == nil && c.err != nil {
+		err, c.err = c.err, nil
+	}
+
+	return err
+}
+
+func (c *Compiler) compile(node ast.Node) error {
+	switch node := node.(type) {
+	case *ast.Program:
+		for _, s := range node.Statements {
+			if err := c.Compile(s); err != nil {
+				return err
+			}
+		}
+	case *ast.ExpressionStatement:
+		if err := c.Compile(node.Expression); err != nil {
+			return err
+		}
+		c.emit(code.OpPop)
+	case *ast.BlockStatement:
+		for _, s := range node.Statements {
+			if err := c.Compile(s); err != nil {
+				return err
+			}
+		}
+	case *ast.LetStatement:
+		if node.Name == nil {
+			return c.errorf("cannot bind to %s", node.Unquote.String())
+		}
+
+		if _, ok := node.Value.(*ast.FunctionLiteral); ok {
+			c.symbolTable.Define(node.Name.Value)
+		}
+
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		c.storeSymbol(c.symbolTable.Define(node.Name.Value))
+	case *ast.ReturnStatement:
+		if err := c.Compile(node.ReturnValue); err != nil {
+			return err
+		}
+		if err := c.leaveTryBlocks(0); err != nil {
+			return err
+		}
+		c.emit(code.OpReturnValue)
+	case *ast.Identifier:
+		symbol, ok := c.symbolTable.Resolve(node.Value)
+		if !ok {
+			symbol = c.symbolTable.Global().Define(node.Value)
+		}
+		c.loadSymbol(symbol)
+	case *ast.IntegerLiteral:
+		integer := &object.Integer{Value: node.Value}
+		c.emit(code.OpConstant, c.addConstant(integer))
+	case *ast.FloatLiteral:
+		float := &object.Float{Value: node.Value}
+		c.emit(code.OpConstant, c.addConstant(float))
+	case *ast.StringLiteral:
+		str := &object.String{Value: node.Value}
+		c.emit(code.OpConstant, c.addConstant(str))
+	case *ast.Boolean:
+		if node.Value {
+			c.emit(code.OpTrue)
+		} else {
+			c.emit(code.OpFalse)
+		}
+	case *ast.Null:
+		c.emit(code.OpNull)
+	case *ast.PrefixExpression:
+		if err := c.Compile(node.Right); err != nil {
+			return err
+		}
+
+		switch node.Operator {
+		case "!":
+			c.emit(code.OpBang)
+		case "-":
+			c.emit(code.OpMinus)
+		default:
+			return c.errorf("unknown operator %s", node.Operator)
+		}
+	case *ast.InfixExpression:
+		if err := c.Compile(node.Left); err != nil {
+			return err
+		}
+		if node.Operator == "&&" || node.Operator == "||" {
+			return c.compileLogicalExpression(node)
+		}
+		if err := c.Compile(node.Right); err != nil {
+			return err
+		}
+
+		op, ok := infixOperators[node.Operator]
+		if !ok {
+			return c.errorf("unknown operator %s", node.Operator)
+		}
+		c.emit(op)
+	case *ast.AssignExpression:
+		return c.compileAssignExpression(node)
+	case *ast.IfExpression:
+		if err := c.Compile(node.Condition); err != nil {
+			return err
+		}
+
+		jumpNotTruthyPos := c.emit(code.OpJumpNotTruthy, 9999)
+
+		if err := c.compileBlockValue(node.Consequence); err != nil {
+			return err
+		}
+
+		jumpPos := c.emit(code.OpJump, 9999)
+		if err := c.changeOperand(jumpNotTruthyPos, len(c.currentInstructions())); err != nil {
+			return err
+		}
+
+		if node.Alternative == nil {
+			c.emit(code.OpNull)
+		} else if err := c.compileBlockValue(node.Alternative); err != nil {
+			return err
+		}
+
+		if err := c.changeOperand(jumpPos, len(c.currentInstructions())); err != nil {
+			return err
+		}
+	case *ast.ArrayLiteral:
+		for _, el := range node.Elements {
+			if err := c.Compile(el); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpArray, len(node.Elements))
+	case *ast.HashLiteral:
+		keys := []ast.Expression{}
+		for k := range node.Pairs {
+			keys = append(keys, k)
+		}
+
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+
+		for _, k := range keys {
+			if err := c.Compile(k); err != nil {
+				return err
+			}
+			if err := c.Compile(node.Pairs[k]); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpHash, len(node.Pairs)*2)
+	case *ast.IndexExpression:
+		if err := c.Compile(node.Left); err != nil {
+			return err
+		}
+		if err := c.Compile(node.Index); err != nil {
+			return err
+		}
+		c.emit(code.OpIndex)
+	case *ast.FunctionLiteral:
+		return c.compileFunctionLiteral(node)
+	case *ast.CallExpression:
+		if node.Function.TokenLiteral() == "quote" {
+			return c.errorf("quote is not supported by the bytecode compiler")
+		}
+
+		if err := c.Compile(node.Function); err != nil {
+			return err
+		}
+
+		if hasSpreadArguments(node) {
+			return c.compileSpreadCall(node)
+		}
+
+		for _, arg := range node.Arguments {
+			if err := c.Compile(arg); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpCall, len(node.Arguments))
+	case *ast.SpreadExpression:
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		c.emit(code.OpSpread)
+	case *ast.MacroLiteral:
+		return c.errorf("macro literals must be expanded before compilation")
+	case *ast.WhileExpression:
+		return c.compileWhileExpression(node)
+	case *ast.ForExpression:
+		return c.compileForExpression(node)
+	case *ast.BreakStatement:
+		scope := c.scopes[c.scopeIndex]
+		if len(scope.loops) == 0 {
+			return c.errorf("break outside loop")
+		}
+
+		current := len(scope.loops) - 1
+		if err := c.leaveTryBlocks(scope.loops[current].tryDepth); err != nil {
+			return err
+		}
+
+		pos := c.emit(code.OpJump, 9999)
+		loops := scope.loops
+		loops[current].breakJumps = append(loops[current].breakJumps, pos)
+	case *ast.ContinueStatement:
+		scope := c.scopes[c.scopeIndex]
+		if len(scope.loops) == 0 {
+			return c.errorf("continue outside loop")
+		}
+
+		current := scope.loops[len(scope.loops)-1]
+		if err := c.leaveTryBlocks(current.tryDepth); err != nil {
+			return err
+		}
+
+		c.emit(code.OpJump, current.start)
+	case *ast.TryExpression:
+		return c.compileTryExpression(node)
+	case *ast.ThrowExpression:
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		c.emit(code.OpThrow)
+	}
+
+	return nil
+}
+
+func (c *Compiler) Bytecode() *Bytecode {
+	return &Bytecode{
+		Instructions: c.currentInstructions(),
+		Positions:    c.scopes[c.scopeIndex].positions,
+		Constants:    c.constants,
+		GlobalNames:  c.symbolTable.Global().Names(),
+		Builtins:     c.symbolTable.Builtins(),
+	}
+}
+
+var infixOperators = map[string]code.Opcode{
+	"+":  code.OpAdd,
+	"-":  code.OpSub,
+	"*":  code.OpMul,
+	"/":  code.OpDiv,
+	"%":  code.OpMod,
+	">":  code.OpGreaterThan,
+	"<":  code.OpLessThan,
+	">=": code.OpGreaterEqual,
+	"<=": code.OpLessEqual,
+	"==": code.OpEqual,
+	"!=": code.OpNotEqual,
+}
+
+func (c *Compiler) compileLogicalExpression(node *ast.InfixExpression) error {
+	jump := code.OpJumpNotTruthy
+	if node.Operator == "||" {
+		jump = code.OpJumpTruthy
+	}
+
+	c.emit(code.OpDup, 1)
+	jumpPos := c.emit(jump, 9999)
+	c.emit(code.OpPop)
+
+	if err := c.Compile(node.Right); err != nil {
+		return err
+	}
+
+	return c.changeOperand(jumpPos, len(c.currentInstructions()))
+}
+
+func (c *Compiler) compileAssignExpression(node *ast.AssignExpression) error {
+	operator := strings.TrimSuffix(node.Operator, "=")
+
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		symbol, ok := c.symbolTable.Resolve(target.Value)
+		if !ok {
+			symbol = c.symbolTable.Global().Define(target.Value)
+		}
+
+		if symbol.Scope == BuiltinScope {
+			return c.errorf("cannot assign to builtin: %s", target.Value)
+		}
+
+		if operator != "" {
+			c.loadSymbol(symbol)
+		}
+
+		if err := c.compileAssignedValue(node.Value, operator); err != nil {
+			return err
+		}
+
+		c.assignSymbol(symbol)
+	case *ast.IndexExpression:
+		if err := c.Compile(target.Left); err != nil {
+			return err
+		}
+		if err := c.Compile(target.Index); err != nil {
+			return err
+		}
+
+		if operator != "" {
+			c.emit(code.OpDup, 2)
+			c.emit(code.OpIndex)
+		}
+
+		if err := c.compileAssignedValue(node.Value, operator); err != nil {
+			return err
+		}
+
+		c.emit(code.OpSetIndex)
+	default:
+		return c.errorf("cannot assign to %s", node.Target.String())
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileAssignedValue(value ast.Expression, operator string) error {
+	if err := c.Compile(value); err != nil {
+		return err
+	}
+
+	if operator == "" {
+		return nil
+	}
+
+	op, ok := infixOperators[operator]
+	if !ok {
+		return c.errorf("unknown operator %s", operator)
+	}
+	c.emit(op)
+
+	return nil
+}
+
+func (c *Compiler) compileBlockValue(block *ast.BlockStatement) error {
+	if err := c.Compile(block); err != nil {
+		return err
+	}
+
+	if c.lastInstructionIs(code.OpPop) {
+		c.removeLastPop()
+	} else {
+		c.emit(code.OpNull)
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileWhileExpression(node *ast.WhileExpression) error {
+	start := len(c.currentInstructions())
+
+	if err := c.Compile(node.Condition); err != nil {
+		return err
+	}
+
+	exitPos := c.emit(code.OpJumpNotTruthy, 9999)
+
+	if err := c.compileLoopBody(node.Body, start); err != nil {
+		return err
+	}
+
+	return c.changeOperand(exitPos, len(c.currentInstructions())-1)
+}
+
+func (c *Compiler) compileForExpression(node *ast.ForExpression) error {
+	if err := c.Compile(node.Collection); err != nil {
+		return err
+	}
+
+	c.emit(code.OpIter)
+
+	iterator := c.symbolTable.Define(fmt.Sprintf("#iterator%d", len(c.scopes[c.scopeIndex].loops)))
+	c.storeSymbol(iterator)
+
+	start := len(c.currentInstructions())
+	c.loadSymbol(iterator)
+
+	variables := []*ast.Identifier{node.Value}
+	if node.Key != nil {
+		variables = []*ast.Identifier{node.Value, node.Key}
+	}
+
+	exitPos := c.emit(code.OpIterNext, 9999, len(variables))
+
+	for _, variable := range variables {
+		symbol := c.symbolTable.Define(variable.Value)
+		c.storeSymbol(symbol)
+	}
+
+	if err := c.compileLoopBody(node.Body, start); err != nil {
+		return err
+	}
+
+	return c.changeOperand(exitPos, len(c.currentInstructions())-1, len(variables))
+}
+
+func (c *Compiler) compileLoopBody(body *ast.BlockStatement, start int) error {
+	scopeIndex := c.scopeIndex
+	scope := &c.scopes[scopeIndex]
+	scope.loops = append(scope.loops, loop{start: start, tryDepth: len(scope.tryBlocks)})
+
+	err := c.Compile(body)
+
+	scope = &c.scopes[scopeIndex]
+	current := scope.loops[len(scope.loops)-1]
+	scope.loops = scope.loops[:len(scope.loops)-1]
+
+	if err != nil {
+		return err
+	}
+
+	c.emit(code.OpJump, start)
+	end := c.emit(code.OpNull)
+
+	for _, pos := range current.breakJumps {
+		if err := c.changeOperand(pos, end); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileTryExpression(node *ast.TryExpression) error {
+	tryPos := c.emit(code.OpTry, 9999)
+	if err := c.compileProtected(node.Block, node.Finally); err != nil {
+		return err
+	}
+	doneJumps := []int{c.emit(code.OpJump, 9999)}
+	if err := c.changeOperand(tryPos, len(c.currentInstructions())); err != nil {
+		return err
+	}
+
+	if node.Catch != nil {
+		c.emit(code.OpCatch)
+		symbol := c.symbolTable.Define(node.CatchParameter.Value)
+		c.storeSymbol(symbol)
+
+		if node.Finally == nil {
+			if err := c.compileBlockValue(node.Catch); err != nil {
+				return err
+			}
+			return c.changeOperand(doneJumps[0], len(c.currentInstructions()))
+		}
+
+		catchTryPos := c.emit(code.OpTry, 9999)
+		if err := c.compileProtected(node.Catch, node.Finally); err != nil {
+			return err
+		}
+		doneJumps = append(doneJumps, c.emit(code.OpJump, 9999))
+		if err := c.changeOperand(catchTryPos, len(c.currentInstructions())); err != nil {
+			return err
+		}
+	}
+
+	if err := c.compileFinally(node.Finally); err != nil {
+		return err
+	}
+	c.emit(code.OpThrow)
+
+	for _, pos := range doneJumps {
+		if err := c.changeOperand(pos, len(c.currentInstructions())); err != nil {
+			return err
+		}
+	}
+
+	return c.compileFinally(node.Finally)
+}
+
+func (c *Compiler) compileProtected(block *ast.BlockStatement, finally *ast.BlockStatement) error {
+	scopeIndex := c.scopeIndex
+	scope := &c.scopes[scopeIndex]
+	scope.tryBlocks = append(scope.tryBlocks, tryBlock{finally: finally})
+
+	err := c.compileBlockValue(block)
+
+	scope = &c.scopes[scopeIndex]
+	scope.tryBlocks = scope.tryBlocks[:len(scope.tryBlocks)-1]
+
+	if err != nil {
+		return err
+	}
+
+	c.emit(code.OpEndTry)
+
+	return nil
+}
+
+func (c *Compiler) leaveTryBlocks(depth int) error {
+	scopeIndex := c.scopeIndex
+	tryBlocks := c.scopes[scopeIndex].tryBlocks
+	defer func() { c.scopes[scopeIndex].tryBlocks = tryBlocks }()
+
+	for i := len(tryBlocks) - 1; i >= depth; i-- {
+		c.scopes[scopeIndex].tryBlocks = tryBlocks[:i]
+		c.emit(code.OpEndTry)
+
+		if err := c.compileFinally(tryBlocks[i].finally); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileFinally(finally *ast.BlockStatement) error {
+	if finally == nil {
+		return nil
+	}
+
+	return c.Compile(finally)
+}
+
+func (c *Compiler) compileFunctionLiteral(node *ast.FunctionLiteral) error {
+	c.enterScope()
+
+	for _, name := range boundNames(node.Body, nil) {
+		c.symbolTable.Declare(name)
+	}
+
+	for _, p := range node.Parameters {
+		c.symbolTable.Define(p.Value)
+	}
+
+	if node.Rest != nil {
+		c.symbolTable.Define(node.Rest.Value)
+	}
+
+	numDefaults, err := c.compileDefaults(node)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Compile(node.Body); err != nil {
+		return err
+	}
+
+	if c.lastInstructionIs(code.OpPop) {
+		c.replaceLastPopWithReturn()
+	}
+	if !c.lastInstructionIs(code.OpReturnValue) {
+		c.emit(code.OpReturn)
+	}
+
+	markTailCalls(c.currentInstructions())
+
+	freeSymbols := c.symbolTable.FreeSymbols
+	localNames := c.symbolTable.Names()
+	instructions, positions := c.leaveScope()
+
+	captures := make([]object.Capture, len(freeSymbols))
+	for i, s := range freeSymbols {
+		captures[i] = object.Capture{Name: s.Name, Local: s.Scope == LocalScope, Index: s.Index}
+	}
+
+	compiledFn := &object.CompiledFunction{
+		Name:          node.Name,
+		Instructions:  instructions,
+		Positions:     positions,
+		NumLocals:     len(localNames),
+		NumParameters: len(node.Parameters),
+		NumDefaults:   numDefaults,
+		Rest:          node.Rest != nil,
+		LocalNames:    localNames,
+		Captures:      captures,
+	}
+
+	c.emit(code.OpClosure, c.addConstant(compiledFn))
+
+	return nil
+}
+
+func boundNames(node ast.Node, names []string) []string {
+	switch node := node.(type) {
+	case *ast.BlockStatement:
+		if node == nil {
+			return names
+		}
+		for _, statement := range node.Statements {
+			names = boundNames(statement, names)
+		}
+	case *ast.LetStatement:
+		if node.Name != nil {
+			names = append(names, node.Name.Value)
+		}
+		names = boundNames(node.Value, names)
+	case *ast.ExpressionStatement:
+		names = boundNames(node.Expression, names)
+	case *ast.ReturnStatement:
+		names = boundNames(node.ReturnValue, names)
+	case *ast.IfExpression:
+		names = boundNames(node.Consequence, names)
+		names = boundNames(node.Alternative, names)
+	case *ast.WhileExpression:
+		names = boundNames(node.Body, names)
+	case *ast.ForExpression:
+		names = append(names, node.Value.Value)
+		if node.Key != nil {
+			names = append(names, node.Key.Value)
+		}
+		names = boundNames(node.Body, names)
+	case *ast.TryExpression:
+		names = boundNames(node.Block, names)
+		if node.CatchParameter != nil {
+			names = append(names, node.CatchParameter.Value)
+		}
+		names = boundNames(node.Catch, names)
+		names = boundNames(node.Finally, names)
+	}
+
+	return names
+}
+
+func (c *Compiler) compileDefaults(node *ast.FunctionLiteral) (int, error) {
+	numDefaults := 0
+
+	for i := range node.Parameters {
+		def := node.Default(i)
+		if def == nil {
+			continue
+		}
+
+		jumpPos := c.emit(code.OpJumpIfArg, 9999, i)
+
+		if err := c.Compile(def); err != nil {
+			return 0, err
+		}
+		c.emit(code.OpSetLocal, i)
+
+		if err := c.changeOperand(jumpPos, len(c.currentInstructions()), i); err != nil {
+			return 0, err
+		}
+		numDefaults++
+	}
+
+	return numDefaults, nil
+}
+
+func hasSpreadArguments(node *ast.CallExpression) bool {
+	for _, arg := range node.Arguments {
+		if _, ok := arg.(*ast.SpreadExpression); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Compiler) compileSpreadCall(node *ast.CallExpression) error {
+	for _, arg := range node.Arguments {
+		if err := c.Compile(arg); err != nil {
+			return err
+		}
+	}
+
+	c.emit(code.OpCallSpread, len(node.Arguments))
+
+	return nil
+}
+
+func markTailCalls(ins code.Instructions) {
+	for i := 0; i < len(ins); {
+		def, err := code.Lookup(ins[i])
+		if err != nil {
+			return
+		}
+
+		next := i + 1
+		for _, width := range def.OperandWidths {
+			next += width
+		}
+
+		if code.Opcode(ins[i]) == code.OpCall && returnsAt(ins, next) {
+			ins[i] = byte(code.OpTailCall)
+		}
+
+		i = next
+	}
+}
+
+func returnsAt(ins code.Instructions, pos int) bool {
+	for pos < len(ins) {
+		switch code.Opcode(ins[pos]) {
+		case code.OpReturnValue:
+			return true
+		case code.OpJump:
+			target := int(code.ReadUint16(ins[pos+1:]))
+			if target <= pos {
+				return false
+			}
+			pos = target
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+func (c *Compiler) errorf(format string, a ...interface{}) error {
+	msg := fmt.Sprintf(format, a...)
+
+	if c.position.IsValid() {
+		msg = c.position.String() + ": " + msg
+	}
+
+	return errors.New(msg)
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1
+}
+
+func (c *Compiler) emit(op code.Opcode, operands ...int) int {
+	if err := c.checkOperands(op, operands); err != nil && c.err == nil {
+		c.err = err
+	}
+
+	ins := code.Make(op, operands...)
+	pos := c.addInstruction(ins)
+
+	c.setLastInstruction(op, pos)
+
+	return pos
+}
+
+func (c *Compiler) checkOperands(op code.Opcode, operands []int) error {
+	for i, operand := range operands {
+		if max := code.MaxOperand(op, i); operand > max {
+			return c.errorf("%s: %d (max %d)", operandLimitMessage(op, i), operand, max)
+		}
+	}
+
+	return nil
+}
+
+func operandLimitMessage(op code.Opcode, i int) string {
+	switch op {
+	case code.OpJump, code.OpJumpNotTruthy, code.OpJumpTruthy, code.OpTry, code.OpIterNext:
+		return "jump target out of range"
+	case code.OpConstant, code.OpClosure:
+		return "too many constants"
+	case code.OpGetGlobal, code.OpSetGlobal, code.OpAssignGlobal:
+		return "too many global bindings"
+	case code.OpJumpIfArg:
+		if i == 0 {
+			return "jump target out of range"
+		}
+		return "too many local bindings"
+	case code.OpGetLocal, code.OpSetLocal, code.OpAssignLocal:
+		return "too many local bindings"
+	case code.OpGetFree, code.OpAssignFree:
+		return "too many free variables"
+	case code.OpGetBuiltin:
+		return "too many builtins"
+	case code.OpCall, code.OpTailCall, code.OpCallSpread:
+		return "too many arguments"
+	case code.OpArray, code.OpHash:
+		return "too many elements"
+	default:
+		return "operand out of range"
+	}
+}
+
+func (c *Compiler) addInstruction(ins []byte) int {
+	posNewInstruction := len(c.currentInstructions())
+	updatedInstructions := append(c.currentInstructions(), ins...)
+
+	c.scopes[c.scopeIndex].instructions = updatedInstructions
+
+	if c.position.IsValid() {
+		c.scopes[c.scopeIndex].positions[posNewInstruction] = c.position
+	}
+
+	return posNewInstruction
+}
+
+func (c *Compiler) setLastInstruction(op code.Opcode, pos int) {
+	previous := c.scopes[c.scopeIndex].lastInstruction
+	last := EmittedInstruction{Opcode: op, Position: pos}
+
+	c.scopes[c.scopeIndex].previousInstruction = previous
+	c.scopes[c.scopeIndex].lastInstruction = last
+}
+
+func (c *Compiler) lastInstructionIs(op code.Opcode) bool {
+	if len(c.currentInstructions()) == 0 {
+		return false
+	}
+
+	return c.scopes[c.scopeIndex].lastInstruction.Opcode == op
+}
+
+func (c *Compiler) removeLastPop() {
+	last := c.scopes[c.scopeIndex].lastInstruction
+	previous := c.scopes[c.scopeIndex].previousInstruction
+
+	old := c.currentInstructions()
+	new := old[:last.Position]
+
+	c.scopes[c.scopeIndex].instructions = new
+	c.scopes[c.scopeIndex].lastInstruction = previous
+}
+
+func (c *Compiler) replaceLastPopWithReturn() {
+	lastPos := c.scopes[c.scopeIndex].lastInstruction.Position
+	c.replaceInstruction(lastPos, code.Make(code.OpReturnValue))
+
+	c.scopes[c.scopeIndex].lastInstruction.Opcode = code.OpReturnValue
+}
+
+func (c *Compiler) replaceInstruction(pos int, newInstruction []byte) {
+	ins := c.currentInstructions()
+
+	for i := 0; i < len(newInstruction); i++ {
+		ins[pos+i] = newInstruction[i]
+	}
+}
+
+func (c *Compiler) changeOperand(opPos int, operands ...int) error {
+	op := code.Opcode(c.currentInstructions()[opPos])
+	if err := c.checkOperands(op, operands); err != nil {
+		return err
+	}
+
+	c.replaceInstruction(opPos, code.Make(op, operands...))
+
+	return nil
+}
+
+func (c *Compiler) currentInstructions() code.Instructions {
+	return c.scopes[c.scopeIndex].instructions
+}
+
+func (c *Compiler) enterScope() {
+	scope := CompilationScope{
+		instructions: code.Instructions{},
+		positions:    make(map[int]token.Position),
+	}
+	c.scopes = append(c.scopes, scope)
+	c.scopeIndex++
+
+	c.symbolTable = NewEnclosedSymbolTable(c.symbolTable)
+}
+
+func (c *Compiler) leaveScope() (code.Instructions, map[int]token.Position) {
+	scope := c.scopes[c.scopeIndex]
+
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.scopeIndex--
+
+	c.symbolTable = c.symbolTable.Outer
+
+	return scope.instructions, scope.positions
+}
+
+func (c *Compiler) loadSymbol(s Symbol) {
+	switch s.Scope {
+	case GlobalScope:
+		c.emit(code.OpGetGlobal, s.Index)
+	case LocalScope:
+		c.emit(code.OpGetLocal, s.Index)
+	case BuiltinScope:
+		c.emit(code.OpGetBuiltin, s.Index)
+	case FreeScope:
+		c.emit(code.OpGetFree, s.Index)
+	}
+}
+
+func (c *Compiler) assignSymbol(s Symbol) {
+	switch s.Scope {
+	case GlobalScope:
+		c.emit(code.OpAssignGlobal, s.Index)
+	case LocalScope:
+		c.emit(code.OpAssignLocal, s.Index)
+	case FreeScope:
+		c.emit(code.OpAssignFree, s.Index)
+	}
+}
+
+func (c *Compiler) storeSymbol(s Symbol) {
+	switch s.Scope {
+	case GlobalScope:
+		c.emit(code.OpSetGlobal, s.Index)
+	case LocalScope:
+		c.emit(code.OpSetLocal, s.Index)
+	}
+}
@@ -0,0 +1,145 @@
+package compiler
+
+import "monkeylang/object"
+
+type SymbolScope string
+
+const (
+	GlobalScope  SymbolScope = "GLOBAL"
+	LocalScope   SymbolScope = "LOCAL"
+	BuiltinScope SymbolScope = "BUILTIN"
+	FreeScope    SymbolScope = "FREE"
+)
+
+type Symbol struct {
+	Name  string
+	Scope SymbolScope
+	Index int
+}
+
+type SymbolTable struct {
+	Outer *SymbolTable
+
+	FreeSymbols []Symbol
+
+	store          map[string]Symbol
+	declared       map[string]bool
+	numDefinitions int
+
+	builtins *object.BuiltinRegistry
+}
+
+func NewSymbolTable() *SymbolTable {
+	s := make(map[string]Symbol)
+	free := []Symbol{}
+	return &SymbolTable{store: s, FreeSymbols: free}
+}
+
+func NewSymbolTableWithBuiltins(builtins *object.BuiltinRegistry) *SymbolTable {
+	s := NewSymbolTable()
+	s.builtins = builtins
+	return s
+}
+
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	s := NewSymbolTable()
+	s.Outer = outer
+	return s
+}
+
+func (s *SymbolTable) Define(name string) Symbol {
+	scope := GlobalScope
+	if s.Outer != nil {
+		scope = LocalScope
+	}
+
+	if symbol, ok := s.store[name]; ok && symbol.Scope == scope {
+		return symbol
+	}
+
+	symbol := Symbol{Name: name, Scope: scope, Index: s.numDefinitions}
+	s.store[name] = symbol
+	s.numDefinitions++
+
+	return symbol
+}
+
+func (s *SymbolTable) Declare(name string) {
+	if s.declared == nil {
+		s.declared = map[string]bool{}
+	}
+
+	s.declared[name] = true
+}
+
+func (s *SymbolTable) DefineBuiltin(index int, name string) Symbol {
+	symbol := Symbol{Name: name, Scope: BuiltinScope, Index: index}
+	s.store[name] = symbol
+	return symbol
+}
+
+func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
+	return s.resolve(name, false)
+}
+
+func (s *SymbolTable) resolve(name string, fromInner bool) (Symbol, bool) {
+	obj, ok := s.store[name]
+	if !ok && fromInner && s.declared[name] {
+		obj, ok = s.Define(name), true
+	}
+	if !ok && s.Outer == nil && s.builtins != nil {
+		if index, found := s.builtins.Index(name); found {
+			return Symbol{Name: name, Scope: BuiltinScope, Index: index}, true
+		}
+	}
+	if !ok && s.Outer != nil {
+		obj, ok = s.Outer.resolve(name, true)
+		if !ok {
+			return obj, ok
+		}
+
+		if obj.Scope == GlobalScope || obj.Scope == BuiltinScope {
+			return obj, ok
+		}
+
+		free := s.defineFree(obj)
+		return free, true
+	}
+
+	return obj, ok
+}
+
+func (s *SymbolTable) Global() *SymbolTable {
+	global := s
+	for global.Outer != nil {
+		global = global.Outer
+	}
+
+	return global
+}
+
+func (s *SymbolTable) Builtins() *object.BuiltinRegistry {
+	return s.Global().builtins
+}
+
+func (s *SymbolTable) Names() []string {
+	names := make([]string, s.numDefinitions)
+
+	for name, symbol := range s.store {
+		if symbol.Scope == GlobalScope || symbol.Scope == LocalScope {
+			names[symbol.Index] = name
+		}
+	}
+
+	return names
+}
+
+func (s *SymbolTable) defineFree(original Symbol) Symbol {
+	s.FreeSymbols = append(s.FreeSymbols, original)
+
+	symbol := Symbol{Name: original.Name, Index: len(s.FreeSymbols) - 1}
+	symbol.Scope = FreeScope
+
+	s.store[original.Name] = symbol
+	return symbol
+}
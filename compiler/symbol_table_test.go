@@ -0,0 +1,119 @@
+package compiler
+
+import (
+	"testing"
+
+	"monkeylang/object"
+)
+
+func TestResolveNestedLocals(t *testing.T) {
+	global := NewSymbolTable()
+	global.Define("a")
+
+	firstLocal := NewEnclosedSymbolTable(global)
+	firstLocal.Define("b")
+
+	secondLocal := NewEnclosedSymbolTable(firstLocal)
+	secondLocal.Define("c")
+
+	expected := map[string]Symbol{
+		"a": {Name: "a", Scope: GlobalScope, Index: 0},
+		"b": {Name: "b", Scope: FreeScope, Index: 0},
+		"c": {Name: "c", Scope: LocalScope, Index: 0},
+	}
+
+	for name, sym := range expected {
+		result, ok := secondLocal.Resolve(name)
+		if !ok {
+			t.Errorf("name %s not resolvable", name)
+			continue
+		}
+
+		if result != sym {
+			t.Errorf("expected %s to resolve to %+v, got=%+v", name, sym, result)
+		}
+	}
+
+	if len(secondLocal.FreeSymbols) != 1 || secondLocal.FreeSymbols[0].Name != "b" {
+		t.Errorf("wrong free symbols. got=%+v", secondLocal.FreeSymbols)
+	}
+}
+
+func TestDefineReusesExistingSlot(t *testing.T) {
+	global := NewSymbolTable()
+	global.DefineBuiltin(0, "len")
+
+	first := global.Define("a")
+	second := global.Define("a")
+	if first != second {
+		t.Errorf("redefinition allocated a new slot. first=%+v, second=%+v", first, second)
+	}
+
+	shadowed := global.Define("len")
+	expected := Symbol{Name: "len", Scope: GlobalScope, Index: 1}
+	if shadowed != expected {
+		t.Errorf("builtin not shadowed by global. want=%+v, got=%+v", expected, shadowed)
+	}
+
+	names := global.Names()
+	if len(names) != 2 || names[0] != "a" || names[1] != "len" {
+		t.Errorf("wrong names. got=%v", names)
+	}
+}
+
+func TestResolveDeclaredFromInnerScope(t *testing.T) {
+	global := NewSymbolTable()
+	global.Define("y")
+
+	outer := NewEnclosedSymbolTable(global)
+	outer.Declare("y")
+
+	if result, _ := outer.Resolve("y"); result.Scope != GlobalScope {
+		t.Errorf("declared name resolved in its own scope before definition. got=%+v", result)
+	}
+
+	inner := NewEnclosedSymbolTable(outer)
+	expected := Symbol{Name: "y", Scope: FreeScope, Index: 0}
+	if result, ok := inner.Resolve("y"); !ok || result != expected {
+		t.Errorf("declared name not captured from inner scope. want=%+v, got=%+v", expected, result)
+	}
+
+	defined := outer.Define("y")
+	if want := (Symbol{Name: "y", Scope: LocalScope, Index: 0}); defined != want {
+		t.Errorf("definition did not reuse the captured slot. want=%+v, got=%+v", want, defined)
+	}
+}
+
+func TestResolveBuiltinsFromRegistry(t *testing.T) {
+	builtins := object.NewEmptyBuiltinRegistry()
+	builtins.Define("len", &object.Builtin{})
+	builtins.Define("puts", &object.Builtin{})
+
+	global := NewSymbolTableWithBuiltins(builtins)
+	local := NewEnclosedSymbolTable(global)
+
+	expected := Symbol{Name: "puts", Scope: BuiltinScope, Index: 1}
+	if result, ok := local.Resolve("puts"); !ok || result != expected {
+		t.Errorf("expected puts to resolve to %+v, got=%+v (%t)", expected, result, ok)
+	}
+
+	if len(local.FreeSymbols) != 0 {
+		t.Errorf("builtin captured as free symbol. got=%+v", local.FreeSymbols)
+	}
+
+	builtins.Remove("puts")
+	if result, ok := local.Resolve("puts"); ok {
+		t.Errorf("removed builtin still resolvable. got=%+v", result)
+	}
+
+	builtins.Define("clock", &object.Builtin{})
+	expected = Symbol{Name: "clock", Scope: BuiltinScope, Index: 2}
+	if result, ok := local.Resolve("clock"); !ok || result != expected {
+		t.Errorf("expected clock to resolve to %+v, got=%+v (%t)", expected, result, ok)
+	}
+
+	shadowed := global.Define("len")
+	if result, _ := local.Resolve("len"); result != shadowed {
+		t.Errorf("builtin not shadowed by global. want=%+v, got=%+v", shadowed, result)
+	}
+}
@@ -0,0 +1,252 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"monkeylang/ast"
+	"monkeylang/compiler"
+	"monkeylang/evaluator"
+	"monkeylang/object"
+	"monkeylang/vm"
+)
+
+type Backend string
+
+const (
+	EvaluatorBackend Backend = "eval"
+	VMBackend        Backend = "vm"
+)
+
+func ParseBackend(name string) (Backend, error) {
+	switch Backend(name) {
+	case EvaluatorBackend, VMBackend:
+		return Backend(name), nil
+	default:
+		return "", fmt.Errorf("unknown backend %q, expected %q or %q", name, EvaluatorBackend, VMBackend)
+	}
+}
+
+type executor interface {
+	execute(ctx context.Context, program *ast.Program, limits object.Limits) object.Object
+	call(ctx context.Context, fn object.Object, args []object.Object, limits object.Limits) object.Object
+	get(name string) (object.Object, bool)
+	set(name string, value object.Object)
+	setMaxRecursionDepth(depth int)
+}
+
+type Engine struct {
+	builtins *object.BuiltinRegistry
+	limits   object.Limits
+	macroEnv *object.Environment
+	macros   *evaluator.MacroExpander
+	executor executor
+}
+
+func New(backend Backend) *Engine {
+	return NewWithBuiltins(backend, object.NewBuiltinRegistry())
+}
+
+func NewWithBuiltins(backend Backend, builtins *object.BuiltinRegistry) *Engine {
+	macroEnv := object.NewEnvironmentWithBuiltins(builtins)
+
+	return &Engine{
+		builtins: builtins,
+		macroEnv: macroEnv,
+		macros:   evaluator.NewMacroExpander(macroEnv),
+		executor: newExecutor(backend, builtins),
+	}
+}
+
+func (e *Engine) Builtins() *object.BuiltinRegistry {
+	return e.builtins
+}
+
+func (e *Engine) Limits() object.Limits {
+	return e.limits
+}
+
+func (e *Engine) SetLimits(limits object.Limits) {
+	e.limits = limits
+}
+
+func (e *Engine) SetMaxRecursionDepth(depth int) {
+	e.executor.setMaxRecursionDepth(depth)
+}
+
+func (e *Engine) SetMacroTrace(trace func(evaluator.ExpansionStep)) {
+	e.macros.SetTrace(trace)
+}
+
+func (e *Engine) SetHygienicMacros(hygienic bool) {
+	e.macros.SetHygienic(hygienic)
+}
+
+func (e *Engine) Execute(program *ast.Program) object.Object {
+	return e.ExecuteContext(context.Background(), program)
+}
+
+func (e *Engine) ExecuteContext(ctx context.Context, program *ast.Program) object.Object {
+	expanded, errs := e.Expand(program)
+	if len(errs) != 0 {
+		return macroError(errs)
+	}
+
+	return e.ExecuteExpanded(ctx, expanded)
+}
+
+func (e *Engine) Expand(program *ast.Program) (*ast.Program, evaluator.MacroErrors) {
+	evaluator.DefineMacros(program, e.macroEnv)
+
+	expanded, errs := e.macros.Expand(program)
+	if len(errs) != 0 {
+		return nil, errs
+	}
+
+	return expanded.(*ast.Program), nil
+}
+
+func (e *Engine) ExecuteExpanded(ctx context.Context, program *ast.Program) object.Object {
+	return e.executor.execute(ctx, program, e.limits)
+}
+
+func macroError(errs evaluator.MacroErrors) *object.Error {
+	first := errs[0]
+
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, fmt.Sprintf("error expanding macro %s: %s", err.Macro, err.Message))
+	}
+
+	return &object.Error{
+		Kind:     object.MACRO_ERROR,
+		Message:  strings.Join(messages, "\n"),
+		Position: first.Position,
+		Value:    first.Value,
+	}
+}
+
+func (e *Engine) Call(fn object.Object, args []object.Object) object.Object {
+	return e.CallContext(context.Background(), fn, args)
+}
+
+func (e *Engine) CallContext(ctx context.Context, fn object.Object, args []object.Object) object.Object {
+	return e.executor.call(ctx, fn, args, e.limits)
+}
+
+func (e *Engine) Get(name string) (object.Object, bool) {
+	return e.executor.get(name)
+}
+
+func (e *Engine) Set(name string, value object.Object) {
+	e.executor.set(name, value)
+}
+
+func newExecutor(backend Backend, builtins *object.BuiltinRegistry) executor {
+	if backend == VMBackend {
+		return &vmExecutor{
+			symbolTable:       compiler.NewSymbolTableWithBuiltins(builtins),
+			constants:         []object.Object{},
+			globals:           make([]object.Object, vm.GlobalsSize),
+			maxRecursionDepth: object.DEFAULT_MAX_RECURSION_DEPTH,
+		}
+	}
+
+	return &evaluatorExecutor{env: object.NewEnvironmentWithBuiltins(builtins)}
+}
+
+type evaluatorExecutor struct {
+	env *object.Environment
+}
+
+func (e *evaluatorExecutor) execute(ctx context.Context, program *ast.Program, limits object.Limits) object.Object {
+	return evaluator.EvalContext(ctx, program, e.env, limits)
+}
+
+func (e *evaluatorExecutor) call(ctx context.Context, fn object.Object, args []object.Object, limits object.Limits) object.Object {
+	return evaluator.ApplyContext(ctx, fn, args, limits)
+}
+
+func (e *evaluatorExecutor) get(name string) (object.Object, bool) {
+	if value, ok := e.env.Get(name); ok {
+		return value, true
+	}
+
+	if builtin, ok := e.env.Builtin(name); ok {
+		return builtin, true
+	}
+
+	return nil, false
+}
+
+func (e *evaluatorExecutor) set(name string, value object.Object) {
+	e.env.Set(name, value)
+}
+
+func (e *evaluatorExecutor) setMaxRecursionDepth(depth int) {
+	e.env.SetMaxRecursionDepth(depth)
+}
+
+type vmExecutor struct {
+	symbolTable *compiler.SymbolTable
+	constants   []object.Object
+	globals     []object.Object
+
+	maxRecursionDepth int
+}
+
+func (e *vmExecutor) execute(ctx context.Context, program *ast.Program, limits object.Limits) object.Object {
+	comp := compiler.NewWithState(e.symbolTable, e.constants)
+	if err := comp.Compile(program); err != nil {
+		return &object.Error{Message: err.Error()}
+	}
+
+	bytecode := comp.Bytecode()
+	e.constants = bytecode.Constants
+
+	machine := vm.NewWithGlobalsStore(bytecode, e.globals)
+	machine.SetMaxRecursionDepth(e.maxRecursionDepth)
+
+	return machine.RunContext(ctx, limits)
+}
+
+func (e *vmExecutor) call(ctx context.Context, fn object.Object, args []object.Object, limits object.Limits) object.Object {
+	bytecode := &compiler.Bytecode{
+		Constants:   e.constants,
+		GlobalNames: e.symbolTable.Names(),
+		Builtins:    e.symbolTable.Builtins(),
+	}
+
+	machine := vm.NewWithGlobalsStore(bytecode, e.globals)
+	machine.SetMaxRecursionDepth(e.maxRecursionDepth)
+
+	return machine.CallContext(ctx, fn, args, limits)
+}
+
+func (e *vmExecutor) get(name string) (object.Object, bool) {
+	symbol, ok := e.symbolTable.Resolve(name)
+	if !ok {
+		return nil, false
+	}
+
+	switch symbol.Scope {
+	case compiler.GlobalScope:
+		value := e.globals[symbol.Index]
+		return value, value != nil
+	case compiler.BuiltinScope:
+		builtin := e.symbolTable.Builtins().At(symbol.Index)
+		return builtin, builtin != nil
+	default:
+		return nil, false
+	}
+}
+
+func (e *vmExecutor) set(name string, value object.Object) {
+	symbol := e.symbolTable.Define(name)
+	e.globals[symbol.Index] = value
+}
+
+func (e *vmExecutor) setMaxRecursionDepth(depth int) {
+	e.maxRecursionDepth = depth
+}
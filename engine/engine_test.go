@@ -0,0 +1,338 @@
+package engine
+
+import (
+	"testing"
+
+	"monkeylang/evaluator"
+	"monkeylang/lexer"
+	"monkeylang/object"
+	"monkeylang/parser"
+)
+
+func TestExecuteExpandsMacrosAndKeepsState(t *testing.T) {
+	inputs := []string{
+		`let unless = macro(cond, cons, alt) { quote(if (!(unquote(cond))) { unquote(cons) } else { unquote(alt) }) };`,
+		`let double = fn(x) { x * 2 };`,
+		`unless(10 > 5, 0, double(21))`,
+	}
+
+	for _, backend := range []Backend{EvaluatorBackend, VMBackend} {
+		e := New(backend)
+
+		var result object.Object
+		for _, input := range inputs {
+			l := lexer.New(input)
+			p := parser.New(l)
+			result = e.Execute(p.ParseProgram())
+		}
+
+		integer, ok := result.(*object.Integer)
+		if !ok {
+			t.Errorf("[%s] result is not Integer. got=%T (%+v)", backend, result, result)
+			continue
+		}
+
+		if integer.Value != 42 {
+			t.Errorf("[%s] wrong result. want=42, got=%d", backend, integer.Value)
+		}
+	}
+}
+
+func TestParseBackend(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Backend
+		valid    bool
+	}{
+		{"eval", EvaluatorBackend, true},
+		{"vm", VMBackend, true},
+		{"jit", "", false},
+	}
+
+	for _, tt := range tests {
+		backend, err := ParseBackend(tt.input)
+		if (err == nil) != tt.valid {
+			t.Errorf("ParseBackend(%q) error = %v, valid = %t", tt.input, err, tt.valid)
+		}
+
+		if backend != tt.expected {
+			t.Errorf("ParseBackend(%q) = %q, want %q", tt.input, backend, tt.expected)
+		}
+	}
+}
+
+func TestBuiltinsArePerEngine(t *testing.T) {
+	for _, backend := range []Backend{EvaluatorBackend, VMBackend} {
+		sandboxed := New(backend)
+		sandboxed.Builtins().Remove("puts")
+		sandboxed.Builtins().Define("len", &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				return &object.Integer{Value: -1}
+			},
+		})
+
+		standard := New(backend)
+
+		tests := []struct {
+			engine   *Engine
+			input    string
+			expected string
+		}{
+			{sandboxed, `len("abc")`, "-1"},
+			{standard, `len("abc")`, "3"},
+			{sandboxed, `puts`, "ERROR: 1:1: identifier not found: puts"},
+			{standard, `puts`, "builtin function"},
+			{sandboxed, `let f = fn() { first(builtins()) }; f()`, "abs"},
+			{sandboxed, `len(builtins())`, "-1"},
+			{standard, `len(builtins())`, "20"},
+		}
+
+		for _, tt := range tests {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			result := tt.engine.Execute(p.ParseProgram())
+
+			if result.Inspect() != tt.expected {
+				t.Errorf("[%s] %q wrong result. want=%q, got=%q", backend, tt.input, tt.expected, result.Inspect())
+			}
+		}
+	}
+}
+
+func TestRemovedBuiltinIsNotFound(t *testing.T) {
+	for _, backend := range []Backend{EvaluatorBackend, VMBackend} {
+		e := New(backend)
+
+		inputs := []string{
+			`let size = fn(x) { len(x) };`,
+			`size("abc")`,
+		}
+
+		var result object.Object
+		for _, input := range inputs {
+			l := lexer.New(input)
+			p := parser.New(l)
+			result = e.Execute(p.ParseProgram())
+		}
+
+		if result.Inspect() != "3" {
+			t.Errorf("[%s] wrong result before removal. got=%q", backend, result.Inspect())
+		}
+
+		e.Builtins().Remove("len")
+
+		l := lexer.New(`size("abc")`)
+		p := parser.New(l)
+		result = e.Execute(p.ParseProgram())
+
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Errorf("[%s] result is not Error. got=%T (%+v)", backend, result, result)
+			continue
+		}
+
+		if errObj.Message != "identifier not found: len" {
+			t.Errorf("[%s] wrong error message. got=%q", backend, errObj.Message)
+		}
+	}
+}
+
+func TestMoreThan256Builtins(t *testing.T) {
+	for _, backend := range []Backend{EvaluatorBackend, VMBackend} {
+		e := New(backend)
+
+		for i := 0; i < 300; i++ {
+			value := int64(i)
+			name := string([]byte{'h', byte('a' + i/26), byte('a' + i%26)})
+			e.Builtins().Define(name, &object.Builtin{
+				Fn: func(args ...object.Object) object.Object {
+					return &object.Integer{Value: value}
+				},
+			})
+		}
+
+		tests := []struct {
+			input    string
+			expected string
+		}{
+			{`hle()`, "290"},
+			{`let f = fn() { hln() + had() }; f()`, "302"},
+			{`len("abc")`, "3"},
+		}
+
+		for _, tt := range tests {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			result := e.Execute(p.ParseProgram())
+
+			if result.Inspect() != tt.expected {
+				t.Errorf("[%s] %q wrong result. want=%q, got=%q", backend, tt.input, tt.expected, result.Inspect())
+			}
+		}
+	}
+}
+
+func TestMacroExpansionErrors(t *testing.T) {
+	for _, backend := range []Backend{EvaluatorBackend, VMBackend} {
+		e := New(backend)
+
+		steps := []evaluator.ExpansionStep{}
+		e.SetMacroTrace(func(step evaluator.ExpansionStep) {
+			steps = append(steps, step)
+		})
+
+		l := lexer.New("let twice = macro(x) { quote(unquote(x) * 2) };\nlet broken = macro() { 1 };\nlet a = twice(2);\nbroken();")
+		p := parser.New(l)
+		result := e.Execute(p.ParseProgram())
+
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Errorf("[%s] result is not Error. got=%T (%+v)", backend, result, result)
+			continue
+		}
+
+		if errObj.Kind != object.MACRO_ERROR {
+			t.Errorf("[%s] wrong error kind. got=%s", backend, errObj.Kind)
+		}
+
+		if errObj.Position.String() != "4:1" {
+			t.Errorf("[%s] wrong error position. got=%s", backend, errObj.Position)
+		}
+
+		expected := "error expanding macro broken: macro must return a quoted AST node, got INTEGER"
+		if errObj.Message != expected {
+			t.Errorf("[%s] wrong error message. want=%q, got=%q", backend, expected, errObj.Message)
+		}
+
+		if len(steps) != 1 || steps[0].Macro != "twice" || steps[0].After != "(2 * 2)" {
+			t.Errorf("[%s] wrong expansion trace. got=%+v", backend, steps)
+		}
+	}
+}
+
+func TestMultipleMacroExpansionErrors(t *testing.T) {
+	for _, backend := range []Backend{EvaluatorBackend, VMBackend} {
+		e := New(backend)
+
+		l := lexer.New("let broken = macro() { 1 };\nlet angry = macro() { throw \"no\" };\nbroken();\nangry();")
+		p := parser.New(l)
+		result := e.Execute(p.ParseProgram())
+
+		errObj, ok := result.(*object.Error)
+		if !ok {
+			t.Errorf("[%s] result is not Error. got=%T (%+v)", backend, result, result)
+			continue
+		}
+
+		expected := "error expanding macro broken: macro must return a quoted AST node, got INTEGER\n" +
+			"error expanding macro angry: Error: no"
+		if errObj.Message != expected {
+			t.Errorf("[%s] wrong error message. want=%q, got=%q", backend, expected, errObj.Message)
+		}
+
+		if errObj.Position.String() != "3:1" {
+			t.Errorf("[%s] wrong error position. got=%s", backend, errObj.Position)
+		}
+
+		if errObj.Value == nil || errObj.Value.Inspect() != "1" {
+			t.Errorf("[%s] wrong offending value. got=%v", backend, errObj.Value)
+		}
+	}
+}
+
+func TestHygienicMacros(t *testing.T) {
+	input := `
+let tmp = 10;
+let twice = macro(x) { quote(if (true) { let tmp = unquote(x) * 2; tmp }) };
+let swap = macro(a, b) { quote(fn() { let tmp = unquote(a); unquote(a) = unquote(b); unquote(b) = tmp; }()) };
+let scale = macro(x) { quote(fn() { let factor = 3; unquote(x) * factor + base }()) };
+let pick = macro() { quote([fn() { let base = 0; base }(), base]) };
+let other = 20;
+let base = 1;
+let factor = 5;
+let doubled = twice(tmp);
+swap(other, tmp);
+[doubled, tmp, other, scale(factor), pick()]
+`
+
+	tests := []struct {
+		hygienic bool
+		expected string
+	}{
+		{false, "[20, 20, 20, 10, [0, 1]]"},
+		{true, "[20, 20, 10, 16, [0, 1]]"},
+	}
+
+	for _, backend := range []Backend{EvaluatorBackend, VMBackend} {
+		for _, tt := range tests {
+			e := New(backend)
+			e.SetHygienicMacros(tt.hygienic)
+
+			l := lexer.New(input)
+			p := parser.New(l)
+			result := e.Execute(p.ParseProgram())
+
+			if result.Inspect() != tt.expected {
+				t.Errorf("[%s] hygienic=%t wrong result. want=%s, got=%s", backend, tt.hygienic, tt.expected, result.Inspect())
+			}
+		}
+	}
+}
+
+func TestScopedRecursiveAndVariadicMacros(t *testing.T) {
+	input := `
+let sum = macro(head, ...tail) {
+    if (len(tail) == 0) {
+        head
+    } else {
+        quote(unquote(head) + sum(...unquote(tail)))
+    }
+};
+let scale = fn(x) {
+    let triple = macro(y) { quote(unquote(y) * 3) };
+    triple(x)
+};
+[sum(1, 2, 3), scale(sum(4, 5))]
+`
+
+	for _, backend := range []Backend{EvaluatorBackend, VMBackend} {
+		e := New(backend)
+
+		l := lexer.New(input)
+		p := parser.New(l)
+		result := e.Execute(p.ParseProgram())
+
+		if result.Inspect() != "[6, 27]" {
+			t.Errorf("[%s] wrong result. want=%s, got=%s", backend, "[6, 27]", result.Inspect())
+		}
+	}
+}
+
+func TestMacrosBuildingNodes(t *testing.T) {
+	input := `
+let curry = macro(f) {
+    let params = fields(f)["parameters"];
+    let body = fields(f)["body"];
+    let i = len(params) - 1;
+    while (i >= 0) {
+        body = node("FunctionLiteral", {"parameters": [params[i]], "body": body});
+        i -= 1;
+    };
+    body;
+};
+let add = curry(fn(x, y, z) { x + y * z });
+add(1)(2)(3)
+`
+
+	for _, backend := range []Backend{EvaluatorBackend, VMBackend} {
+		e := New(backend)
+
+		l := lexer.New(input)
+		p := parser.New(l)
+		result := e.Execute(p.ParseProgram())
+
+		if result.Inspect() != "7" {
+			t.Errorf("[%s] wrong result. want=%s, got=%s", backend, "7", result.Inspect())
+		}
+	}
+}
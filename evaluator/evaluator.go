@@ -1,19 +1,52 @@
 package evaluator
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"strings"
 
 	"monkeylang/ast"
 	"monkeylang/object"
+	"monkeylang/token"
 )
 
 var (
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
-	NULL  = &object.Null{}
+	TRUE  = object.TRUE
+	FALSE = object.FALSE
+	NULL  = object.NULL
 )
 
+func EvalContext(ctx context.Context, node ast.Node, env *object.Environment, limits object.Limits) object.Object {
+	return withLimits(ctx, env, limits, func() object.Object {
+		return Eval(node, env)
+	})
+}
+
+func withLimits(ctx context.Context, env *object.Environment, limits object.Limits, run func() object.Object) object.Object {
+	previous := env.Limiter()
+	env.SetLimiter(object.NewLimiter(ctx, limits))
+	defer env.SetLimiter(previous)
+
+	return run()
+}
+
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	if err := env.Limiter().Step(); err != nil {
+		err.Position = node.Pos()
+		return err
+	}
+
+	result := eval(node, env)
+
+	if err, ok := result.(*object.Error); ok && !err.Position.IsValid() {
+		err.Position = node.Pos()
+	}
+
+	return result
+}
+
+func eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
 		return evalProgram(node, env)
@@ -21,6 +54,8 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return Eval(node.Expression, env)
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.Boolean:
 		return nativeBoolToBoolean(node.Value)
 	case *ast.PrefixExpression:
@@ -34,11 +69,16 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(left) {
 			return left
 		}
+		if node.Operator == "&&" || node.Operator == "||" {
+			return evalLogicalExpression(node, left, env)
+		}
 		right := Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
-		return evalInfixExpression(node.Operator, left, right)
+		return allocate(env, evalInfixExpression(node.Operator, left, right))
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
 	case *ast.IfExpression:
@@ -50,6 +90,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return &object.ReturnValue{Value: val}
 	case *ast.LetStatement:
+		if node.Name == nil {
+			return newError(object.ERROR, "cannot bind to %s", node.Unquote.String())
+		}
+
 		val := Eval(node.Value, env)
 		if isError(val) {
 			return val
@@ -60,23 +104,38 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
-		return &object.Function{Parameters: params, Env: env, Body: body}
+		return allocate(env, &object.Function{
+			Name:       node.Name,
+			Parameters: params,
+			Defaults:   node.Defaults,
+			Rest:       node.Rest,
+			Env:        env,
+			Body:       body,
+		})
 	case *ast.CallExpression:
 		if node.Function.TokenLiteral() == "quote" {
 			return quote(node.Arguments[0], env)
 		}
 
+		if node.Function.TokenLiteral() == "unquote" {
+			return allocate(env, unquote(node, env))
+		}
+
 		function := Eval(node.Function, env)
 		if isError(function) {
 			return function
 		}
 
-		args := evalExpressions(node.Arguments, env)
+		args := evalArguments(node.Arguments, env)
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
 
-		return applyFunction(function, args)
+		if _, ok := function.(*object.Builtin); ok {
+			return allocate(env, applyFunction(node, function, args))
+		}
+
+		return applyFunction(node, function, args)
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 	case *ast.ArrayLiteral:
@@ -84,7 +143,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if len(elements) == 1 && isError(elements[0]) {
 			return elements[0]
 		}
-		return &object.Array{Elements: elements}
+		return allocate(env, &object.Array{Elements: elements})
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
@@ -96,9 +155,25 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return evalIndexExpression(left, index)
 	case *ast.HashLiteral:
-		return evalHashLiteral(node, env)
+		return allocate(env, evalHashLiteral(node, env))
 	case *ast.Null:
 		return NULL
+	case *ast.WhileExpression:
+		return evalWhileExpression(node, env)
+	case *ast.ForExpression:
+		return evalForExpression(node, env)
+	case *ast.BreakStatement:
+		return &object.Break{Position: node.Pos()}
+	case *ast.ContinueStatement:
+		return &object.Continue{Position: node.Pos()}
+	case *ast.TryExpression:
+		return evalTryExpression(node, env)
+	case *ast.ThrowExpression:
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		return object.NewThrownError(val)
 	}
 
 	return nil
@@ -115,6 +190,8 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 			return result.Value
 		case *object.Error:
 			return result
+		case *object.Break, *object.Continue:
+			return loopControlError(result)
 		}
 	}
 
@@ -127,8 +204,7 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 	for _, statement := range block.Statements {
 		result = Eval(statement, env)
 		if result != nil {
-			rt := result.Type()
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+			if isControlFlow(result) {
 				return result
 			}
 		}
@@ -144,7 +220,7 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 	case "-":
 		return evalMinusPrefixOperatorExpression(right)
 	default:
-		return newError("unknown operator: %s%s", operator, right.Type())
+		return newError(object.TYPE_ERROR, "unknown operator: %s%s", operator, right.Type())
 	}
 }
 
@@ -162,12 +238,14 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
-		return newError("unknown operator: -%s", right.Type())
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: -right.Value}
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
+		return newError(object.TYPE_ERROR, "unknown operator: -%s", right.Type())
 	}
-
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
 }
 
 func evalInfixExpression(
@@ -178,16 +256,29 @@ func evalInfixExpression(
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left, right)
 	case operator == "==":
 		return nativeBoolToBoolean(left == right)
 	case operator == "!=":
 		return nativeBoolToBoolean(left != right)
 	case left.Type() != right.Type():
-		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
-	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
-		return evalStringInfixExpression(operator, left, right)
+		return newError(object.TYPE_ERROR, "type mismatch: %s %s %s", left.Type(), operator, right.Type())
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalLogicalExpression(ie *ast.InfixExpression, left object.Object, env *object.Environment) object.Object {
+	switch {
+	case ie.Operator == "&&" && !isTruthy(left):
+		return left
+	case ie.Operator == "||" && isTruthy(left):
+		return left
+	default:
+		return Eval(ie.Right, env)
 	}
 }
 
@@ -207,29 +298,105 @@ func evalIntegerInfixExpression(
 	case "*":
 		return &object.Integer{Value: leftVal * rightVal}
 	case "/":
+		if rightVal == 0 {
+			return newError(object.ZERO_DIVISION_ERROR, "division by zero")
+		}
 		return &object.Integer{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError(object.ZERO_DIVISION_ERROR, "modulo by zero")
+		}
+		return &object.Integer{Value: leftVal % rightVal}
 	case "<":
 		return nativeBoolToBoolean(leftVal < rightVal)
 	case ">":
 		return nativeBoolToBoolean(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBoolean(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBoolean(leftVal >= rightVal)
 	case "==":
 		return nativeBoolToBoolean(leftVal == rightVal)
 	case "!=":
 		return nativeBoolToBoolean(leftVal != rightVal)
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+		return newError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
 }
 
-func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
-	if operator != "+" {
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+func evalFloatInfixExpression(
+	operator string,
+	left object.Object,
+	right object.Object,
+) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "<":
+		return nativeBoolToBoolean(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBoolean(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBoolean(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBoolean(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBoolean(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBoolean(leftVal != rightVal)
+	default:
+		return newError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
 
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	default:
+		return 0
+	}
+}
+
+func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
 
-	return &object.String{Value: leftVal + rightVal}
+	switch operator {
+	case "+":
+		return &object.String{Value: leftVal + rightVal}
+	case "<":
+		return nativeBoolToBoolean(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBoolean(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBoolean(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBoolean(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBoolean(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBoolean(leftVal != rightVal)
+	default:
+		return newError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
 }
 
 func nativeBoolToBoolean(b bool) *object.Boolean {
@@ -256,14 +423,223 @@ func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Obje
 	}
 }
 
+func evalWhileExpression(we *ast.WhileExpression, env *object.Environment) object.Object {
+	for {
+		condition := Eval(we.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+
+		if !isTruthy(condition) {
+			return NULL
+		}
+
+		if result, done := evalLoopBody(we.Body, env); done {
+			return result
+		}
+	}
+}
+
+func evalForExpression(fe *ast.ForExpression, env *object.Environment) object.Object {
+	collection := Eval(fe.Collection, env)
+	if isError(collection) {
+		return collection
+	}
+
+	iterator, ok := object.NewIterator(collection)
+	if !ok {
+		return newError(object.TYPE_ERROR, "cannot iterate over %s", collection.Type())
+	}
+
+	for {
+		key, value, ok := iterator.Next()
+		if !ok {
+			return NULL
+		}
+
+		if fe.Key != nil {
+			env.Set(fe.Key.Value, key)
+			env.Set(fe.Value.Value, value)
+		} else {
+			env.Set(fe.Value.Value, iterator.Item(key, value))
+		}
+
+		if result, done := evalLoopBody(fe.Body, env); done {
+			return result
+		}
+	}
+}
+
+func evalLoopBody(body *ast.BlockStatement, env *object.Environment) (object.Object, bool) {
+	result := Eval(body, env)
+
+	switch result.(type) {
+	case *object.Break:
+		return NULL, true
+	case *object.ReturnValue, *object.Error:
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+func isControlFlow(obj object.Object) bool {
+	switch obj.Type() {
+	case object.RETURN_VALUE_OBJ, object.ERROR_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+		return true
+	default:
+		return false
+	}
+}
+
+func loopControlError(obj object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.Break:
+		err := newError(object.ERROR, "break outside loop")
+		err.Position = obj.Position
+		return err
+	case *object.Continue:
+		err := newError(object.ERROR, "continue outside loop")
+		err.Position = obj.Position
+		return err
+	default:
+		return obj
+	}
+}
+
+func evalTryExpression(te *ast.TryExpression, env *object.Environment) object.Object {
+	result := Eval(te.Block, env)
+
+	if err, ok := result.(*object.Error); ok && !err.Catchable() {
+		return err
+	}
+
+	if err, ok := result.(*object.Error); ok && te.Catch != nil {
+		env.Set(te.CatchParameter.Value, err.CaughtValue())
+		result = Eval(te.Catch, env)
+	}
+
+	if te.Finally != nil {
+		final := Eval(te.Finally, env)
+		if final != nil && isControlFlow(final) {
+			return final
+		}
+	}
+
+	return result
+}
+
+func evalAssignExpression(ae *ast.AssignExpression, env *object.Environment) object.Object {
+	switch target := ae.Target.(type) {
+	case *ast.Identifier:
+		return evalIdentifierAssignment(ae, target, env)
+	case *ast.IndexExpression:
+		return evalIndexAssignment(ae, target, env)
+	default:
+		return newError(object.ERROR, "cannot assign to %s", ae.Target.String())
+	}
+}
+
+func evalIdentifierAssignment(ae *ast.AssignExpression, target *ast.Identifier, env *object.Environment) object.Object {
+	var current object.Object
+	if ae.Operator != "=" {
+		current = evalIdentifier(target, env)
+		if isError(current) {
+			return current
+		}
+	}
+
+	val := evalAssignedValue(ae, current, env)
+	if isError(val) {
+		return val
+	}
+
+	if _, ok := env.Assign(target.Value, val); ok {
+		return val
+	}
+
+	if _, ok := env.Builtin(target.Value); ok {
+		return newError(object.NAME_ERROR, "cannot assign to builtin: %s", target.Value)
+	}
+
+	return newError(object.NAME_ERROR, "identifier not found: %s", target.Value)
+}
+
+func evalIndexAssignment(ae *ast.AssignExpression, target *ast.IndexExpression, env *object.Environment) object.Object {
+	left := Eval(target.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	index := Eval(target.Index, env)
+	if isError(index) {
+		return index
+	}
+
+	var current object.Object
+	if ae.Operator != "=" {
+		current = evalIndexExpression(left, index)
+		if isError(current) {
+			return current
+		}
+	}
+
+	val := evalAssignedValue(ae, current, env)
+	if isError(val) {
+		return val
+	}
+
+	return evalSetIndex(left, index, val)
+}
+
+func evalAssignedValue(ae *ast.AssignExpression, current object.Object, env *object.Environment) object.Object {
+	val := Eval(ae.Value, env)
+	if isError(val) || current == nil {
+		return val
+	}
+
+	return evalInfixExpression(strings.TrimSuffix(ae.Operator, "="), current, val)
+}
+
+// Arrays and hashes are mutable: index assignment updates the collection in
+// place, so every binding that refers to it observes the change.
+func evalSetIndex(left, index, val object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		idx, ok := index.(*object.Integer)
+		if !ok {
+			return newError(object.TYPE_ERROR, "array index must be INTEGER, got %s", index.Type())
+		}
+
+		if idx.Value < 0 || idx.Value >= int64(len(left.Elements)) {
+			return newError(object.INDEX_ERROR, "index out of range: %d", idx.Value)
+		}
+
+		left.Elements[idx.Value] = val
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError(object.TYPE_ERROR, "unusable as hash key: %s", index.Type())
+		}
+
+		left.Pairs[key.HashKey()] = object.HashPair{Key: index, Value: val}
+	default:
+		return newError(object.TYPE_ERROR, "index assignment not supported: %s", left.Type())
+	}
+
+	return val
+}
+
 func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
+	case left.Type() == object.ERROR_VALUE_OBJ:
+		return evalErrorValueIndexExpression(left, index)
 	default:
-		return newError("index operator not supported: %s", left.Type())
+		return newError(object.TYPE_ERROR, "index operator not supported: %s", left.Type())
 	}
 }
 
@@ -272,7 +648,7 @@ func evalHashIndexExpression(hash, index object.Object) object.Object {
 
 	key, ok := index.(object.Hashable)
 	if !ok {
-		return newError("unusable as hash key: %s", index.Type())
+		return newError(object.TYPE_ERROR, "unusable as hash key: %s", index.Type())
 	}
 
 	pair, ok := hashObject.Pairs[key.HashKey()]
@@ -283,6 +659,20 @@ func evalHashIndexExpression(hash, index object.Object) object.Object {
 	return pair.Value
 }
 
+func evalErrorValueIndexExpression(errorValue, index object.Object) object.Object {
+	name, ok := index.(*object.String)
+	if !ok {
+		return newError(object.TYPE_ERROR, "error fields must be STRING, got %s", index.Type())
+	}
+
+	field, ok := errorValue.(*object.ErrorValue).Field(name.Value)
+	if !ok {
+		return NULL
+	}
+
+	return field
+}
+
 func evalArrayIndexExpression(array, index object.Object) object.Object {
 	arrayObject := array.(*object.Array)
 	idx := index.(*object.Integer).Value
@@ -308,8 +698,16 @@ func isTruthy(obj object.Object) bool {
 	}
 }
 
-func newError(format string, a ...interface{}) *object.Error {
-	return &object.Error{Message: fmt.Sprintf(format, a...)}
+func allocate(env *object.Environment, obj object.Object) object.Object {
+	if err := env.Limiter().Allocate(obj); err != nil {
+		return err
+	}
+
+	return obj
+}
+
+func newError(kind string, format string, a ...interface{}) *object.Error {
+	return &object.Error{Kind: kind, Message: fmt.Sprintf(format, a...)}
 }
 
 func isError(obj object.Object) bool {
@@ -325,11 +723,11 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 		return val
 	}
 
-	if builtin, ok := builtins[node.Value]; ok {
+	if builtin, ok := env.Builtin(node.Value); ok {
 		return builtin
 	}
 
-	return newError("identifier not found: " + node.Value)
+	return newError(object.NAME_ERROR, "identifier not found: %s", node.Value)
 }
 
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
@@ -348,27 +746,157 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return result
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+func evalArguments(exps []ast.Expression, env *object.Environment) []object.Object {
+	var result []object.Object
+
+	for _, e := range exps {
+		spread, ok := e.(*ast.SpreadExpression)
+		if !ok {
+			evaluated := Eval(e, env)
+			if isError(evaluated) {
+				return []object.Object{evaluated}
+			}
+
+			result = append(result, evaluated)
+			continue
+		}
+
+		evaluated := Eval(spread.Value, env)
+		if isError(evaluated) {
+			return []object.Object{evaluated}
+		}
+
+		array, ok := evaluated.(*object.Array)
+		if !ok {
+			err := newError(object.TYPE_ERROR, "spread argument must be ARRAY, got %s", evaluated.Type())
+			err.Position = spread.Pos()
+			return []object.Object{err}
+		}
+
+		result = append(result, array.Elements...)
+	}
+
+	return result
+}
+
+func Apply(fn object.Object, args []object.Object) object.Object {
+	return applyFunction(nil, fn, args)
+}
+
+func ApplyContext(ctx context.Context, fn object.Object, args []object.Object, limits object.Limits) object.Object {
+	function, ok := fn.(*object.Function)
+	if !ok {
+		return Apply(fn, args)
+	}
+
+	return withLimits(ctx, function.Env, limits, func() object.Object {
+		return Apply(fn, args)
+	})
+}
+
+func applyFunction(call *ast.CallExpression, fn object.Object, args []object.Object) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn, args)
-		evaluated := Eval(fn.Body, extendedEnv)
-		return unwrapReturnValue(evaluated)
+		if err := fn.CheckArity(len(args)); err != nil {
+			return err
+		}
+
+		if !fn.Env.EnterCall() {
+			err := newError(object.RECURSION_ERROR, "maximum recursion depth exceeded")
+			err.Position = callPosition(call)
+			return err
+		}
+		defer fn.Env.LeaveCall()
+
+		limiter := fn.Env.Limiter()
+		if err := limiter.Enter(); err != nil {
+			return err
+		}
+		defer limiter.Leave()
+
+		caller, tailCalls := "", 0
+
+		for {
+			evaluated := evalFunctionBody(fn, args)
+
+			if tc, ok := evaluated.(*tailCall); ok {
+				if next, ok := tc.fn.(*object.Function); ok && next.CheckArity(len(tc.args)) == nil {
+					caller, tailCalls = fn.Name, tailCalls+1
+					call, fn, args = tc.call, next, tc.args
+					continue
+				}
+
+				evaluated = applyTailCall(tc, fn.Env)
+			}
+
+			if err, ok := evaluated.(*object.Error); ok {
+				err.Stack = append(err.Stack, object.StackFrame{
+					Function:  fn.Name,
+					Position:  callPosition(call),
+					Arguments: len(args),
+					Caller:    caller,
+					TailCalls: tailCalls,
+				})
+			}
+
+			return unwrapReturnValue(evaluated)
+		}
 	case *object.Builtin:
 		return fn.Fn(args...)
 	default:
-		return newError("not a function: %s", fn.Type())
+		return newError(object.TYPE_ERROR, "not a function: %s", fn.Type())
 	}
 }
 
-func extendFunctionEnv(function *object.Function, args []object.Object) *object.Environment {
+func callPosition(call *ast.CallExpression) token.Position {
+	if call == nil {
+		return token.Position{}
+	}
+
+	return call.Pos()
+}
+
+func evalFunctionBody(fn *object.Function, args []object.Object) object.Object {
+	env, err := extendFunctionEnv(fn, args)
+	if err != nil {
+		return err
+	}
+
+	return loopControlError(evalTail(fn.Body, env))
+}
+
+func extendFunctionEnv(function *object.Function, args []object.Object) (*object.Environment, object.Object) {
 	env := object.NewEnclosedEnvironment(function.Env)
 
 	for paramIdx, param := range function.Parameters {
-		env.Set(param.Value, args[paramIdx])
+		if paramIdx < len(args) {
+			env.Set(param.Value, args[paramIdx])
+			continue
+		}
+
+		value := Eval(function.Defaults[paramIdx], env)
+		if isError(value) {
+			return nil, value
+		}
+
+		env.Set(param.Value, value)
+	}
+
+	if function.Rest != nil {
+		rest := []object.Object{}
+		if len(args) > len(function.Parameters) {
+			rest = append(rest, args[len(function.Parameters):]...)
+		}
+
+		array := allocate(env, &object.Array{Elements: rest})
+		if isError(array) {
+			return nil, array
+		}
+
+		env.Set(function.Rest.Value, array)
 	}
 
-	return env
+	return env, nil
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
@@ -390,7 +918,7 @@ func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Obje
 
 		hashKey, ok := key.(object.Hashable)
 		if !ok {
-			return newError("unusable as a hash key: %s", key.Type())
+			return newError(object.TYPE_ERROR, "unusable as a hash key: %s", key.Type())
 		}
 
 		value := Eval(valueNode, env)
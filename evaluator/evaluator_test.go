@@ -1,13 +1,54 @@
 package evaluator
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"monkeylang/compiler"
 	"monkeylang/lexer"
 	"monkeylang/object"
 	"monkeylang/parser"
+	"monkeylang/token"
+	"monkeylang/vm"
 )
 
+const (
+	treeWalkingBackend = "eval"
+	bytecodeBackend    = "vm"
+)
+
+var backendFlag = flag.String("backend", "all", "backend to run evaluator tests against: eval, vm or all")
+
+var backend = treeWalkingBackend
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	backends := []string{treeWalkingBackend, bytecodeBackend}
+	if *backendFlag != "all" {
+		backends = []string{*backendFlag}
+	}
+
+	code := 0
+	for _, b := range backends {
+		backend = b
+		code |= m.Run()
+	}
+
+	os.Exit(code)
+}
+
+func skipUnlessTreeWalking(t *testing.T) {
+	if backend != treeWalkingBackend {
+		t.Skipf("not supported by the %q backend", backend)
+	}
+}
+
 func TestEvalIntegerExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -41,6 +82,35 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14", 3.14},
+		{".5", 0.5},
+		{"1e-9", 1e-9},
+		{"-2.5", -2.5},
+
+		{"1.5 + 1.5", 3},
+		{"0.1 * 3", 0.30000000000000004},
+		{"7.5 / 2.5", 3},
+		{"10 - 0.5", 9.5},
+
+		// mixed operands promote to float
+		{"1 + 0.5", 1.5},
+		{"0.5 + 1", 1.5},
+		{"1 / 4.0", 0.25},
+		{"3 * 2.0", 6},
+		{"(5 + 10 * 2 + 15 / 3) * 0.5", 15},
+	}
+
+	for _, testCase := range tests {
+		evaluated := testEval(testCase.input)
+		testFloatObject(t, evaluated, testCase.expected)
+	}
+}
+
 func TestEvalBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -59,6 +129,31 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"1 != 1", false},
 		{"1 == 2", false},
 		{"1 != 2", true},
+		{"1 <= 1", true},
+		{"2 <= 1", false},
+		{"1 >= 1", true},
+		{"1 >= 2", false},
+
+		// float comparisons
+		{"1.5 < 2.5", true},
+		{"1.5 > 2.5", false},
+		{"0.1 + 0.2 == 0.3", false},
+		{"1.0 == 1", true},
+		{"1 != 1.0", false},
+		{"2 > 1.5", true},
+		{"-0.5 < 0", true},
+		{"1.5 <= 1.5", true},
+		{"1 >= 1.5", false},
+
+		// string comparisons
+		{`"a" < "b"`, true},
+		{`"b" < "a"`, false},
+		{`"apple" > "app"`, true},
+		{`"abc" <= "abc"`, true},
+		{`"abd" <= "abc"`, false},
+		{`"Z" >= "a"`, false},
+		{`"a" + "b" == "ab"`, true},
+		{`"a" != "a"`, false},
 
 		// boolean comparisons
 		{"true == true", true},
@@ -99,6 +194,42 @@ func TestEvalBangOperator(t *testing.T) {
 	}
 }
 
+func TestLogicalOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"true && true", true},
+		{"true && false", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"1 && 2", 2},
+		{"null && 2", nil},
+		{"0 || 5", 0},
+		{`null || "default"`, "default"},
+		{"false && undefined", false},
+		{"true || undefined", true},
+		{"true && undefined", "identifier not found: undefined"},
+		{"1 < 2 && 2 < 3", true},
+		{"1 > 2 || 2 >= 3", false},
+		{"false || true && false", false},
+		{`let h = null; h && h["a"]`, nil},
+		{"let n = 0; let f = fn() { n += 1; true }; false && f(); true || f(); true && f(); n", 1},
+		{"let f = fn(x) { x > 0 && x < 10 }; [f(5), f(15)] == [true, false]", false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		if expected, ok := tt.expected.(bool); ok {
+			testBooleanObject(t, evaluated, expected)
+			continue
+		}
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
 func TestIfElseExpressions(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -125,12 +256,26 @@ func TestIfElseExpressions(t *testing.T) {
 }
 
 func testEval(input string) object.Object {
+	return testEvalContext(context.Background(), input, object.Limits{})
+}
+
+func testEvalContext(ctx context.Context, input string, limits object.Limits) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
 	program := p.ParseProgram()
+
+	if backend == bytecodeBackend {
+		comp := compiler.New()
+		if err := comp.Compile(program); err != nil {
+			return &object.Error{Message: err.Error()}
+		}
+
+		return vm.New(comp.Bytecode()).RunContext(ctx, limits)
+	}
+
 	env := object.NewEnvironment()
 
-	return Eval(program, env)
+	return EvalContext(ctx, program, env, limits)
 }
 
 func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
@@ -149,6 +294,21 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	return true
 }
 
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float. got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%g, want=%g", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
 func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
 	result, ok := obj.(*object.Boolean)
 
@@ -219,6 +379,14 @@ func TestErrorHandling(t *testing.T) {
 			"-true",
 			"unknown operator: -BOOLEAN",
 		},
+		{
+			"1.5 + true",
+			"type mismatch: FLOAT + BOOLEAN",
+		},
+		{
+			`2.5 + "a"`,
+			"type mismatch: FLOAT + STRING",
+		},
 		{
 			"true + false;",
 			"unknown operator: BOOLEAN + BOOLEAN",
@@ -246,6 +414,7 @@ func TestErrorHandling(t *testing.T) {
 		{"foobar", "identifier not found: foobar"},
 		{`"Hello" - "World`, "unknown operator: STRING - STRING"},
 		{`{"name": "Monkey"}[fn(x) {x}];`, "unusable as hash key: FUNCTION"},
+		{"fn(a, b) { a }(1)", "wrong number of arguments: want=2, got=1"},
 	}
 
 	for _, tt := range tests {
@@ -281,6 +450,8 @@ func TestLetStatements(t *testing.T) {
 }
 
 func TestFunctionObject(t *testing.T) {
+	skipUnlessTreeWalking(t)
+
 	input := "fn(x) { x + 2; };"
 
 	evaluated := testEval(input)
@@ -338,6 +509,82 @@ func TestClosures(t *testing.T) {
 	testIntegerObject(t, testEval(input), 4)
 }
 
+func TestFunctionParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let f = fn(a, b = 2) { a + b }; f(1)", 3},
+		{"let f = fn(a, b = 2) { a + b }; f(1, 5)", 6},
+		{"let f = fn(a = 1, b = a * 10) { a + b }; f()", 11},
+		{"let f = fn(a = 1, b = a * 10) { a + b }; f(2)", 22},
+		{"let x = 5; let f = fn(a = x) { a }; f()", 5},
+		{"let f = fn(a = [], b = {}) { len(a) }; f()", 0},
+		{"let f = fn(...rest) { rest }; f()", []int64{}},
+		{"let f = fn(...rest) { rest }; f(1, 2, 3)", []int64{1, 2, 3}},
+		{"let f = fn(a, ...rest) { rest }; f(1, 2, 3)", []int64{2, 3}},
+		{"let f = fn(a, b = 2, ...rest) { [a, b, len(rest)] }; f(1)", []int64{1, 2, 0}},
+		{"let f = fn(a, b = 2, ...rest) { [a, b, len(rest)] }; f(1, 3, 5, 7)", []int64{1, 3, 2}},
+		{"let add = fn(a, b) { a + b }; add(...[1, 2])", 3},
+		{"let add = fn(a, b, c) { a + b + c }; add(1, ...[2], ...[], 3)", 6},
+		{"let f = fn(...rest) { rest }; let args = [1, 2]; f(...args, 3, ...args)", []int64{1, 2, 3, 1, 2}},
+		{"len(...[[1, 2, 3]])", 3},
+		{"let f = fn(a) { a }; f(...[1, 2])", "wrong number of arguments: want=1, got=2"},
+		{"let f = fn(a, b) { a }; f()", "wrong number of arguments: want=2, got=0"},
+		{"let f = fn(a, b) { a }; f(1, 2, 3)", "wrong number of arguments: want=2, got=3"},
+		{"let f = fn(a, b = 2) { a }; f()", "wrong number of arguments: want=1..2, got=0"},
+		{"let f = fn(a, b = 2) { a }; f(1, 2, 3)", "wrong number of arguments: want=1..2, got=3"},
+		{"let f = fn(a, b, ...rest) { a }; f(1)", "wrong number of arguments: want=2 or more, got=1"},
+		{"let f = fn(a = 1 + true) { a }; f()", "type mismatch: INTEGER + BOOLEAN"},
+		{"let f = fn(a) { a }; f(...1)", "spread argument must be ARRAY, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFunctionParameterErrors(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedKind     string
+		expectedPosition string
+	}{
+		{"let f = fn(a, b) { a };\nf(1)", object.ARGUMENT_ERROR, "2:1"},
+		{"let f = fn(a) { a };\nf(1, ...2)", object.TYPE_ERROR, "2:6"},
+		{"let f = fn(a, b = 1 + true) { a };\nf(1)", object.TYPE_ERROR, "1:19"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Kind != tt.expectedKind {
+			t.Errorf("wrong error kind for %q. want=%s, got=%s", tt.input, tt.expectedKind, errObj.Kind)
+		}
+
+		if errObj.Position.String() != tt.expectedPosition {
+			t.Errorf("wrong error position for %q. want=%s, got=%s", tt.input, tt.expectedPosition, errObj.Position)
+		}
+	}
+}
+
+func TestFunctionInspect(t *testing.T) {
+	skipUnlessTreeWalking(t)
+
+	evaluated := testEval("fn(a, b = 2, ...rest) { a }")
+
+	expected := "fn(a, b = 2, ...rest) {\na\n}"
+	if evaluated.Inspect() != expected {
+		t.Errorf("wrong Inspect output. want=%q, got=%q", expected, evaluated.Inspect())
+	}
+}
+
 func TestStringLiteral(t *testing.T) {
 	input := `"Hello world"`
 
@@ -387,6 +634,33 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`rest([1, 2, 3, 4])`, []int64{2, 3, 4}},
 		{`push([], 1)`, []int64{1}},
 		{`push([1], 2, 3, 4)`, []int64{1, 2, 3, 4}},
+		{`int(3.99)`, 3},
+		{`int(-3.99)`, -3},
+		{`int("42")`, 42},
+		{`int(7)`, 7},
+		{`int("4.2")`, `could not parse "4.2" as integer`},
+		{`int(true)`, "argument to `int` not supported. got BOOLEAN"},
+		{`int(float("NaN"))`, "cannot convert NaN to INTEGER"},
+		{`int(float("-Inf"))`, "cannot convert -Inf to INTEGER"},
+		{`int(1e300)`, "cannot convert 1e+300 to INTEGER"},
+		{`int(-9.3e18)`, "cannot convert -9.3e+18 to INTEGER"},
+		{`int(-9.2e18)`, -9200000000000000000},
+		{`float(2)`, 2.0},
+		{`float("0.25")`, 0.25},
+		{`float("x")`, `could not parse "x" as float`},
+		{`abs(-5)`, 5},
+		{`abs(-2.5)`, 2.5},
+		{`floor(2.7)`, 2.0},
+		{`floor(-2.5)`, -3.0},
+		{`ceil(2.1)`, 3.0},
+		{`ceil(4)`, 4},
+		{`round(2.5)`, 3.0},
+		{`round(2.345, 2)`, 2.35},
+		{`round(1.5, "2")`, "second argument to `round` must be INTEGER. got STRING"},
+		{`round("x")`, "argument to `round` not supported. got STRING"},
+		{`len(builtins())`, 20},
+		{`if (first(builtins()) == "abs") { 1 } else { 0 }`, 1},
+		{`builtins(1)`, "wrong number of arguments. want=0, got=1"},
 	}
 
 	for _, tt := range tests {
@@ -395,6 +669,8 @@ func TestBuiltinFunctions(t *testing.T) {
 		switch expected := tt.expected.(type) {
 		case int:
 			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
 		case string:
 			errObj, ok := evaluated.(*object.Error)
 			if !ok {
@@ -606,6 +882,18 @@ func TestHashIndexExpressions(t *testing.T) {
 			`{false: 5}[false]`,
 			5,
 		},
+		{
+			`{0.5: 5}[0.5]`,
+			5,
+		},
+		{
+			`{0.5: 5}[0.25]`,
+			nil,
+		},
+		{
+			`{2: 5}[2.0]`,
+			5,
+		},
 	}
 
 	for _, tt := range tests {
@@ -618,3 +906,537 @@ func TestHashIndexExpressions(t *testing.T) {
 		}
 	}
 }
+
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedPosition string
+	}{
+		{"5 + true", "1:1"},
+		{"let x = 1;\nlet y = x + z;", "2:13"},
+		{"let f = fn() {\n  -true\n};\nf();", "2:3"},
+		{`len(1)`, "1:1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Position.String() != tt.expectedPosition {
+			t.Errorf("wrong error position. expected=%s, got=%s", tt.expectedPosition, errObj.Position)
+		}
+	}
+}
+
+func TestTryCatchFinally(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`try { 1 } catch (e) { 2 }`, 1},
+		{`try { 1 + true } catch (e) { 2 }`, 2},
+		{`try { 1 + true } catch (e) { e["message"] }`, "type mismatch: INTEGER + BOOLEAN"},
+		{`try { 1 + true } catch (e) { e["kind"] }`, "TypeError"},
+		{`try { foo } catch (e) { e["kind"] }`, "NameError"},
+		{`try { len(1) } catch (e) { e["kind"] }`, "ArgumentError"},
+		{"try {\n  1 + true\n} catch (e) { e[\"position\"] }", "2:3"},
+		{`try { 1 + true } catch (e) { len(e["stack"]) }`, 0},
+		{`try { 1 + true } catch (e) { e["unknown"] }`, nil},
+		{`try { throw {"code": 42} } catch (e) { e["code"] }`, 42},
+		{`try { throw "boom" } catch (e) { e }`, "boom"},
+		{`try { throw 1 } catch (e) { e } + 1`, 2},
+		{`let f = fn() { 1 + true }; try { f() } catch (e) { "caught" }`, "caught"},
+		{`try { try { throw "a" } catch (e) { throw e + "b" } } catch (e) { e }`, "ab"},
+		{`try { try { 1 + true } catch (e) { throw e } } catch (e) { e["position"] }`, "1:13"},
+		{`try { 1 } finally { 2 }`, 1},
+		{`try { throw 1 } catch (e) { 2 } finally { 3 }`, 2},
+		{`fn() { try { return 1 } finally { return 2 } }()`, 2},
+		{`fn() { try { 1 + true } finally { return 3 } }()`, 3},
+		{`fn() { try { throw 1 } catch (e) { return e + 1 } finally { 10 } }()`, 2},
+		{`let f = fn() { try { return 5 } catch (e) { 0 }; 10 }; f()`, 5},
+		{`let f = fn(x) { try { throw x } catch (e) { e * 2 } }; f(1) + f(2)`, 6},
+		{`let f = fn() { try { return 5 } catch (e) { 0 } }; f(); try { 1 + true } catch (e) { 7 }`, 7},
+		{`try { 1 } catch (e) { 2 }; e`, "identifier not found: e"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			switch obj := evaluated.(type) {
+			case *object.String:
+				if obj.Value != expected {
+					t.Errorf("%q: String has wrong value. got=%q, want=%q", tt.input, obj.Value, expected)
+				}
+			case *object.Error:
+				if obj.Message != expected {
+					t.Errorf("%q: wrong error message. got=%q, want=%q", tt.input, obj.Message, expected)
+				}
+			default:
+				t.Errorf("%q: object is not String or Error. got=%T (%+v)", tt.input, evaluated, evaluated)
+			}
+		case nil:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestThrownErrors(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedMessage  string
+		expectedPosition string
+	}{
+		{`throw "boom"`, "boom", "1:1"},
+		{"let f = fn() {\n  throw {\"code\": 1}\n};\nf()", `{code: 1}`, "2:3"},
+		{`try { 1 } finally { throw "late" }`, "late", "1:21"},
+		{`try { throw "a" } catch (e) { throw "b" } finally { 1 }`, "b", "1:31"},
+		{`try { 1 + true } catch (e) { throw e }`, "type mismatch: INTEGER + BOOLEAN", "1:7"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+
+		if errObj.Position.String() != tt.expectedPosition {
+			t.Errorf("wrong error position. expected=%s, got=%s", tt.expectedPosition, errObj.Position)
+		}
+	}
+}
+
+func TestErrorValueInspect(t *testing.T) {
+	evaluated := testEval(`try { 1 + true } catch (e) { e }`)
+
+	errorValue, ok := evaluated.(*object.ErrorValue)
+	if !ok {
+		t.Fatalf("object is not ErrorValue. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := "TypeError at 1:7: type mismatch: INTEGER + BOOLEAN"
+	if errorValue.Inspect() != expected {
+		t.Errorf("wrong Inspect(). expected=%q, got=%q", expected, errorValue.Inspect())
+	}
+}
+
+func TestErrorStackTraces(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedStack []object.StackFrame
+	}{
+		{`1 + true`, nil},
+		{`len(1)`, nil},
+		{
+			"let inner = fn(a, b) { a + b };\nlet outer = fn(x) {\n  inner(x, true) + 1\n};\nouter(1);",
+			[]object.StackFrame{
+				{Function: "inner", Position: token.Position{Line: 3, Column: 3}, Arguments: 2},
+				{Function: "outer", Position: token.Position{Line: 5, Column: 1}, Arguments: 1},
+			},
+		},
+		{
+			"let inner = fn(a, b) { a + b };\nlet outer = fn(x) {\n  inner(x, true)\n};\nouter(1);",
+			[]object.StackFrame{
+				{Function: "inner", Position: token.Position{Line: 3, Column: 3}, Arguments: 2, Caller: "outer", TailCalls: 1},
+			},
+		},
+		{
+			"let f = fn(n) { if (n == 0) { 1 + true } else { f(n - 1) } };\nf(3)",
+			[]object.StackFrame{
+				{Function: "f", Position: token.Position{Line: 1, Column: 49}, Arguments: 1, Caller: "f", TailCalls: 3},
+			},
+		},
+		{
+			"fn() { -true }()",
+			[]object.StackFrame{
+				{Function: "", Position: token.Position{Line: 1, Column: 1}, Arguments: 0},
+			},
+		},
+		{
+			"let f = fn() { 1 + true };\nlet g = fn() { try { f() } catch (e) { throw e } };\ng()",
+			[]object.StackFrame{
+				{Function: "f", Position: token.Position{Line: 2, Column: 22}, Arguments: 0},
+				{Function: "g", Position: token.Position{Line: 3, Column: 1}, Arguments: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if len(errObj.Stack) != len(tt.expectedStack) {
+			t.Errorf("wrong stack length for %q. expected=%d, got=%d (%+v)", tt.input, len(tt.expectedStack), len(errObj.Stack), errObj.Stack)
+			continue
+		}
+
+		for i, expected := range tt.expectedStack {
+			frame := errObj.Stack[i]
+
+			if frame.Function != expected.Function {
+				t.Errorf("stack[%d] has wrong function. expected=%q, got=%q", i, expected.Function, frame.Function)
+			}
+
+			if frame.Position.Line != expected.Position.Line || frame.Position.Column != expected.Position.Column {
+				t.Errorf("stack[%d] has wrong position. expected=%s, got=%s", i, expected.Position, frame.Position)
+			}
+
+			if frame.Arguments != expected.Arguments {
+				t.Errorf("stack[%d] has wrong argument count. expected=%d, got=%d", i, expected.Arguments, frame.Arguments)
+			}
+
+			if frame.Caller != expected.Caller || frame.TailCalls != expected.TailCalls {
+				t.Errorf("stack[%d] has wrong elided tail calls. expected=%q x%d, got=%q x%d",
+					i, expected.Caller, expected.TailCalls, frame.Caller, frame.TailCalls)
+			}
+		}
+	}
+}
+
+func TestCaughtErrorStack(t *testing.T) {
+	input := `
+	let inner = fn(a) { a + true };
+	let outer = fn() { inner(1) + 1 };
+	try { outer() } catch (e) { e["stack"] }
+	`
+
+	evaluated := testEval(input)
+
+	stack, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := []string{"outer", "inner"}
+	if len(stack.Elements) != len(expected) {
+		t.Fatalf("wrong number of stack frames. expected=%d, got=%d", len(expected), len(stack.Elements))
+	}
+
+	for i, name := range expected {
+		frame, ok := stack.Elements[i].(*object.Hash)
+		if !ok {
+			t.Fatalf("stack[%d] is not Hash. got=%T", i, stack.Elements[i])
+		}
+
+		function := frame.Pairs[(&object.String{Value: "function"}).HashKey()].Value
+		if function.Inspect() != name {
+			t.Errorf("stack[%d] has wrong function. expected=%q, got=%q", i, name, function.Inspect())
+		}
+	}
+}
+
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`while (false) { 1 }`, nil},
+		{`let f = fn(n) { let i = 0; while (i < n) { let i = i + 1 }; i }; f(5)`, 5},
+		{`let xs = []; for (x in [1, 2, 3]) { let xs = push(xs, x * 2) }; xs`, []int64{2, 4, 6}},
+		{`let xs = []; for (i, x in [5, 6, 7]) { let xs = push(xs, i) }; xs`, []int64{0, 1, 2}},
+		{`let s = ""; for (c in "abc") { let s = c + s }; s`, "cba"},
+		{`let s = []; for (i, c in "ab") { let s = push(s, i) }; s`, []int64{0, 1}},
+		{`let ks = []; for (k in {3: "c", 1: "a", 2: "b"}) { let ks = push(ks, k) }; ks`, []int64{1, 2, 3}},
+		{`let vs = []; for (k, v in {"b": 2, "a": 1}) { let vs = push(vs, v) }; vs`, []int64{1, 2}},
+		{`let xs = []; for (x in [1, 2, 3, 4]) { if (x == 3) { break }; let xs = push(xs, x) }; xs`, []int64{1, 2}},
+		{`let xs = []; for (x in [1, 2, 3, 4]) { if (x == 2) { continue }; let xs = push(xs, x) }; xs`, []int64{1, 3, 4}},
+		{`let i = 0; while (true) { let i = i + 1; if (i > 9) { break } }; i`, 10},
+		{`let f = fn() { for (x in [1, 2, 3]) { if (x == 2) { return x * 10 } }; 0 }; f()`, 20},
+		{`let n = 0; for (x in [1, 2]) { for (y in [1, 2, 3]) { if (y == 2) { break }; let n = n + 1 } }; n`, 2},
+		{`let n = 0; for (x in [1, 2, 3]) { try { if (x == 2) { break } } finally { let n = n + 1 } }; n`, 2},
+		{`let n = 0; for (x in [1, 2, 3]) { try { continue } catch (e) { 0 }; let n = n + 1 }; try { 1 + true } catch (e) { n }`, 0},
+		{`for (x in [1]) { x }`, nil},
+		{`let sum = 0; for (x in [1, 2.5]) { let sum = sum + x }; sum`, 3.5},
+		{`for (x in 5) { x }`, "cannot iterate over INTEGER"},
+		{`while (1 + true) { 1 }`, "type mismatch: INTEGER + BOOLEAN"},
+		{`for (x in [1]) { x + true }`, "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let x = 1; x = 2; x`, 2},
+		{`let x = 1; x = x + 1`, 2},
+		{`let a = 1; let b = 2; a = b = 5; a + b`, 10},
+		{`let x = 1; let f = fn() { let x = 10; x = 20; x }; f() + x`, 21},
+		{`let total = 0; let add = fn(x) { total += x }; add(2); add(3); total`, 5},
+		{`let counter = fn() { let n = 0; fn() { n += 1 } }; let c = counter(); c(); c(); c()`, 3},
+		{`let counter = fn() { let n = 0; fn() { n += 1 } }; let a = counter(); let b = counter(); a(); a(); b()`, 1},
+		{`let f = fn() { let n = 0; let g = fn() { let h = fn() { n = n + 5 }; h() }; g(); n }; f()`, 5},
+		{`let x = 10; x -= 3; x *= 4; x /= 2; x %= 5; x`, 4},
+		{`let x = 1; x += 0.5; x`, 1.5},
+		{`let s = "a"; s += "b"; s`, "ab"},
+		{`let i = 0; let sum = 0; while (i < 5) { sum += i; i += 1 }; sum`, 10},
+		{`let xs = [1, 2, 3]; xs[1] = 20; xs`, []int64{1, 20, 3}},
+		{`let xs = [1, 2, 3]; xs[0] += 5`, 6},
+		{`let a = [1]; let b = a; b[0] = 9; a[0]`, 9},
+		{`let h = {"a": 1}; h["b"] = 2; h["a"] += 10; h["a"] + h["b"]`, 13},
+		{`let m = {"xs": [1, 2]}; m["xs"][1] *= 10; m["xs"][1]`, 20},
+		{`let n = 0; let xs = [0, 0]; let next = fn() { n += 1; n - 1 }; xs[next()] += 5; n`, 1},
+		{`let xs = [0, 0]; let set = fn(i) { xs[i] = i + 7 }; set(1); xs`, []int64{0, 8}},
+		{`7 % 3`, 1},
+		{`-7 % 3`, -1},
+		{`7.5 % 2`, 1.5},
+		{`x = 5`, "identifier not found: x"},
+		{`x += 1`, "identifier not found: x"},
+		{`let f = fn() { y = 1 }; f()`, "identifier not found: y"},
+		{`let xs = [1]; xs[1] = 2`, "index out of range: 1"},
+		{`let xs = [1]; xs["a"] = 2`, "array index must be INTEGER, got STRING"},
+		{`let s = "ab"; s[0] = "c"`, "index assignment not supported: STRING"},
+		{`let h = {}; h[fn(x) { x }] = 1`, "unusable as hash key: FUNCTION"},
+		{`let x = 1; x += true`, "type mismatch: INTEGER + BOOLEAN"},
+		{`1 / 0`, "division by zero"},
+		{`let x = 1; x %= 0`, "modulo by zero"},
+	}
+
+	for _, tt := range tests {
+		testExpectedObject(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func testExpectedObject(t *testing.T, input string, evaluated object.Object, expected interface{}) {
+	t.Helper()
+
+	switch expected := expected.(type) {
+	case int:
+		testIntegerObject(t, evaluated, int64(expected))
+	case float64:
+		testFloatObject(t, evaluated, expected)
+	case []int64:
+		array, ok := evaluated.(*object.Array)
+		if !ok {
+			t.Errorf("%q: object is not Array. got=%T (%+v)", input, evaluated, evaluated)
+			return
+		}
+
+		if len(array.Elements) != len(expected) {
+			t.Errorf("%q: wrong number of elements. want=%d, got=%d", input, len(expected), len(array.Elements))
+			return
+		}
+
+		for i, element := range expected {
+			testIntegerObject(t, array.Elements[i], element)
+		}
+	case string:
+		switch obj := evaluated.(type) {
+		case *object.String:
+			if obj.Value != expected {
+				t.Errorf("%q: String has wrong value. got=%q, want=%q", input, obj.Value, expected)
+			}
+		case *object.Error:
+			if obj.Message != expected {
+				t.Errorf("%q: wrong error message. got=%q, want=%q", input, obj.Message, expected)
+			}
+		default:
+			t.Errorf("%q: object is not String or Error. got=%T (%+v)", input, evaluated, evaluated)
+		}
+	case nil:
+		testNullObject(t, evaluated)
+	}
+}
+
+func TestLoopControlOutsideLoop(t *testing.T) {
+	skipUnlessTreeWalking(t)
+
+	tests := []struct {
+		input            string
+		expectedMessage  string
+		expectedPosition string
+	}{
+		{"break", "break outside loop", "1:1"},
+		{"let f = fn() {\n  continue\n};\nfor (x in [1]) { f() }", "continue outside loop", "2:3"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+
+		if errObj.Position.String() != tt.expectedPosition {
+			t.Errorf("wrong error position. expected=%s, got=%s", tt.expectedPosition, errObj.Position)
+		}
+	}
+}
+
+func TestExecutionLimits(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	loop := `while (true) {}`
+	recurse := `let f = fn(n) { f(n + 1) }; f(0)`
+	countdown := `let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) } }; f(1000)`
+	grow := `let a = []; while (true) { a = push(a, 1) }`
+
+	tests := []struct {
+		ctx      context.Context
+		input    string
+		limits   object.Limits
+		expected interface{}
+	}{
+		{context.Background(), loop, object.Limits{MaxSteps: 100}, "step limit exceeded: 100 steps"},
+		{context.Background(), `try { ` + loop + ` } catch (e) { 1 } finally { 2 }`, object.Limits{MaxSteps: 100}, "step limit exceeded: 100 steps"},
+		{context.Background(), `let x = 0; while (x < 10) { x += 1 }; x`, object.Limits{MaxSteps: 10000}, 10},
+		{context.Background(), recurse, object.Limits{MaxCallDepth: 10, MaxSteps: 10000}, "step limit exceeded: 10000 steps"},
+		{context.Background(), `let f = fn(n) { 1 + f(n + 1) }; f(0)`, object.Limits{MaxCallDepth: 10}, "call depth limit exceeded: 10 calls"},
+		{context.Background(), `let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) } }; f(5); f(5); f(9)`, object.Limits{MaxCallDepth: 10}, 0},
+		{context.Background(), countdown, object.Limits{MaxCallDepth: 10}, 0},
+		{context.Background(), `let f = fn() { try { f() } catch (e) { 0 } }; f()`, object.Limits{MaxCallDepth: 10}, "call depth limit exceeded: 10 calls"},
+		{context.Background(), grow, object.Limits{MaxAllocations: 50}, "allocation limit exceeded: 50 objects"},
+		{context.Background(), `let s = "a"; while (true) { s = s + s }`, object.Limits{MaxAllocations: 50}, "allocation limit exceeded: 50 objects"},
+		{context.Background(), `len([1, 2, 3]) + {"a": 1}["a"]`, object.Limits{MaxAllocations: 10}, 4},
+		{context.Background(), loop, object.Limits{Timeout: 10 * time.Millisecond}, "time limit exceeded: 10ms"},
+		{canceled, loop, object.Limits{}, "execution canceled: context canceled"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalContext(tt.ctx, tt.input, tt.limits)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+
+		if errObj, ok := evaluated.(*object.Error); ok && errObj.Kind != object.LIMIT_ERROR {
+			t.Errorf("%q wrong error kind. want=%s, got=%s", tt.input, object.LIMIT_ERROR, errObj.Kind)
+		}
+	}
+}
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let count = fn(n, acc) { if (n == 0) { acc } else { count(n - 1, acc + 1) } }; count(300000, 0)`, 300000},
+		{`let count = fn(n) { if (n == 0) { return "done"; } return count(n - 1); }; count(300000)`, "done"},
+		{`let count = fn(n) { n == 0 || count(n - 1) }; count(300000)`, true},
+		{`let f = fn(n) { if (n > 0) { return f(n - 1) }; "done" }; f(100000)`, "done"},
+		{`let f = fn(n) { if (n > 0) { if (n % 2 == 0) { return f(n - 1) } else { return f(n - 1) } }; "done" }; f(100000)`, "done"},
+		{`let f = fn(n) { if (n > 0) { f(n - 1); return n }; "done" }; f(100)`, 100},
+		{`
+let isEven = fn(n) { if (n == 0) { true } else { isOdd(n - 1) } };
+let isOdd = fn(n) { if (n == 0) { false } else { isEven(n - 1) } };
+isEven(300001)`, false},
+		{`
+let sum = fn(n) {
+  let loop = fn(i, acc) { if (i > n) { acc } else { loop(i + 1, acc + i) } };
+  loop(1, 0)
+};
+sum(300000)`, 45000150000},
+		{`let f = fn(x) { len(x) }; f("abc")`, 3},
+		{`let f = fn(a, b) { a }; let g = fn() { f(1) }; g()`, "wrong number of arguments: want=2, got=1"},
+		{`let g = fn() { 5() }; g()`, "not a function: INTEGER"},
+		{`let g = fn(n) { throw n }; let f = fn(n) { try { g(n) } catch (e) { e + 1 } }; f(1)`, 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if b, ok := tt.expected.(bool); ok {
+			testBooleanObject(t, evaluated, b)
+			continue
+		}
+
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestRecursionLimit(t *testing.T) {
+	runaway := "let f = fn(n) {\n  1 + f(n + 1)\n};\n"
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{runaway + "f(0)", "maximum recursion depth exceeded"},
+		{runaway + `try { f(0) } catch (e) { e["kind"] }`, "RecursionError"},
+		{runaway + `try { f(0) } catch (e) { e["position"] }`, "2:7"},
+		{runaway + `try { f(0) } catch (e) { 1 }; try { f(0) } catch (e) { 2 }`, 2},
+		{`let countDown = fn(n) { if (n == 0) { 0 } else { countDown(n - 1) } }; countDown(5000)`, 0},
+		{`let depth = fn(n) { if (n == 0) { 0 } else { 1 + depth(n - 1) } }; depth(9000)`, 9000},
+		{`let depth = fn(n) { if (n == 0) { 0 } else { 1 + depth(n - 1) } }; depth(100000)`, "maximum recursion depth exceeded"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+
+		if errObj, ok := evaluated.(*object.Error); ok && errObj.Kind != object.RECURSION_ERROR {
+			t.Errorf("%q wrong error kind. want=%s, got=%s", tt.input, object.RECURSION_ERROR, errObj.Kind)
+		}
+	}
+}
+
+func TestLetBindingResolution(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let x = 10; let f = fn() { let x = x + 1; x }; [f(), x]`, []int64{11, 10}},
+		{`let outer = fn(x) { fn() { let x = x * 2; x }() }; outer(4)`, 8},
+		{`let f = fn() { let g = fn() { y * 2 }; let y = 21; g() }; f()`, 42},
+		{`let y = 1; let f = fn() { let g = fn() { y }; let y = 2; g() }; [f(), y]`, []int64{2, 1}},
+		{`let f = fn() { let h = fn() { fn() { z }() }; let z = 7; h() }; f()`, 7},
+		{`let f = fn() { let fact = fn(n) { if (n == 0) { 1 } else { n * fact(n - 1) } }; fact(5) }; f()`, 120},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestManyLocalsAndArguments(t *testing.T) {
+	var locals strings.Builder
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&locals, "let v_%s = %d; ", strings.Repeat("a", i), i+1)
+	}
+
+	args := strings.TrimSuffix(strings.Repeat("1, ", 300), ", ")
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let vee = fn() { " + locals.String() + "v_" + strings.Repeat("a", 299) + " }; vee()", 300},
+		{"let vee = fn() { " + locals.String() + "v_" + strings.Repeat("a", 44) + " }; vee()", 45},
+		{"let count = fn(...xs) { len(xs) }; count(" + args + ")", 300},
+		{"let add = fn(...xs) { let sum = 0; for (x in xs) { sum += x }; sum }; add(" + args + ")", 300},
+		{"let first_of = fn(a, ...xs) { a }; let xs = [" + args + "]; first_of(...xs)", 1},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input[:20], evaluated, tt.expected)
+	}
+}
@@ -0,0 +1,155 @@
+package evaluator
+
+import "monkeylang/ast"
+
+type freeIdentifiers struct {
+	scopes []map[string]bool
+	seen   map[string]bool
+	names  []string
+}
+
+func freeIdentifiersOf(node ast.Node) []string {
+	f := &freeIdentifiers{seen: map[string]bool{}}
+	f.node(node)
+	return f.names
+}
+
+func (f *freeIdentifiers) node(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.BlockStatement:
+		if node == nil {
+			return
+		}
+		for _, statement := range node.Statements {
+			f.node(statement)
+		}
+	case *ast.ExpressionStatement:
+		f.node(node.Expression)
+	case *ast.LetStatement:
+		f.node(node.Value)
+	case *ast.ReturnStatement:
+		f.node(node.ReturnValue)
+	case *ast.Identifier:
+		f.reference(node)
+	case *ast.PrefixExpression:
+		f.node(node.Right)
+	case *ast.InfixExpression:
+		f.node(node.Left)
+		f.node(node.Right)
+	case *ast.AssignExpression:
+		f.node(node.Target)
+		f.node(node.Value)
+	case *ast.CallExpression:
+		f.node(node.Function)
+		f.expressions(node.Arguments)
+	case *ast.IndexExpression:
+		f.node(node.Left)
+		f.node(node.Index)
+	case *ast.SpreadExpression:
+		f.node(node.Value)
+	case *ast.ThrowExpression:
+		f.node(node.Value)
+	case *ast.IfExpression:
+		f.node(node.Condition)
+		f.node(node.Consequence)
+		f.node(node.Alternative)
+	case *ast.WhileExpression:
+		f.node(node.Condition)
+		f.node(node.Body)
+	case *ast.ForExpression:
+		f.node(node.Collection)
+		f.node(node.Body)
+	case *ast.TryExpression:
+		f.node(node.Block)
+		f.node(node.Catch)
+		f.node(node.Finally)
+	case *ast.ArrayLiteral:
+		f.expressions(node.Elements)
+	case *ast.HashLiteral:
+		for key, value := range node.Pairs {
+			f.node(key)
+			f.node(value)
+		}
+	case *ast.FunctionLiteral:
+		f.function(node.Parameters, node.Defaults, node.Rest, node.Body)
+	case *ast.MacroLiteral:
+		f.function(node.Parameters, nil, node.Rest, node.Body)
+	}
+}
+
+func (f *freeIdentifiers) function(parameters []*ast.Identifier, defaults []ast.Expression, rest *ast.Identifier, body *ast.BlockStatement) {
+	scope := map[string]bool{}
+	for _, parameter := range parameters {
+		scope[parameter.Value] = true
+	}
+	if rest != nil {
+		scope[rest.Value] = true
+	}
+	declareBindings(body, scope)
+
+	f.scopes = append(f.scopes, scope)
+	defer func() { f.scopes = f.scopes[:len(f.scopes)-1] }()
+
+	f.expressions(defaults)
+	f.node(body)
+}
+
+func (f *freeIdentifiers) reference(identifier *ast.Identifier) {
+	for _, scope := range f.scopes {
+		if scope[identifier.Value] {
+			return
+		}
+	}
+
+	if !f.seen[identifier.Value] {
+		f.seen[identifier.Value] = true
+		f.names = append(f.names, identifier.Value)
+	}
+}
+
+func (f *freeIdentifiers) expressions(expressions []ast.Expression) {
+	for _, expression := range expressions {
+		if expression != nil {
+			f.node(expression)
+		}
+	}
+}
+
+func declareBindings(node ast.Node, scope map[string]bool) {
+	switch node := node.(type) {
+	case *ast.BlockStatement:
+		if node == nil {
+			return
+		}
+		for _, statement := range node.Statements {
+			declareBindings(statement, scope)
+		}
+	case *ast.LetStatement:
+		if node.Name != nil {
+			scope[node.Name.Value] = true
+		}
+		declareBindings(node.Value, scope)
+	case *ast.ExpressionStatement:
+		declareBindings(node.Expression, scope)
+	case *ast.ReturnStatement:
+		declareBindings(node.ReturnValue, scope)
+	case *ast.IfExpression:
+		declareBindings(node.Consequence, scope)
+		declareBindings(node.Alternative, scope)
+	case *ast.WhileExpression:
+		declareBindings(node.Body, scope)
+	case *ast.ForExpression:
+		scope[node.Value.Value] = true
+		if node.Key != nil {
+			scope[node.Key.Value] = true
+		}
+		declareBindings(node.Body, scope)
+	case *ast.TryExpression:
+		declareBindings(node.Block, scope)
+		if node.CatchParameter != nil {
+			scope[node.CatchParameter.Value] = true
+		}
+		declareBindings(node.Catch, scope)
+		declareBindings(node.Finally, scope)
+	}
+}
@@ -0,0 +1,190 @@
+package evaluator
+
+import "monkeylang/ast"
+
+type hygieneScope struct {
+	names map[string]string
+	outer *hygieneScope
+}
+
+type hygiene struct {
+	fromCallSite map[ast.Node]bool
+	gensym       func(string) string
+	scope        *hygieneScope
+	declaring    bool
+}
+
+func (h *hygiene) rename(expansion ast.Node) ast.Node {
+	h.enterScope()
+	defer h.leaveScope()
+
+	h.declaring = true
+	h.node(expansion)
+	h.declaring = false
+
+	return h.node(expansion)
+}
+
+func (h *hygiene) enterScope() {
+	h.scope = &hygieneScope{names: map[string]string{}, outer: h.scope}
+}
+
+func (h *hygiene) leaveScope() {
+	h.scope = h.scope.outer
+}
+
+func (h *hygiene) node(node ast.Node) ast.Node {
+	if h.fromCallSite[node] {
+		return node
+	}
+
+	switch node := node.(type) {
+	case *ast.Program:
+		h.statements(node.Statements)
+	case *ast.BlockStatement:
+		h.block(node)
+	case *ast.ExpressionStatement:
+		node.Expression = h.expression(node.Expression)
+	case *ast.LetStatement:
+		node.Name = h.binding(node.Name)
+		node.Value = h.expression(node.Value)
+	case *ast.ReturnStatement:
+		node.ReturnValue = h.expression(node.ReturnValue)
+	case *ast.Identifier:
+		return h.reference(node)
+	case *ast.PrefixExpression:
+		node.Right = h.expression(node.Right)
+	case *ast.InfixExpression:
+		node.Left = h.expression(node.Left)
+		node.Right = h.expression(node.Right)
+	case *ast.AssignExpression:
+		node.Target = h.expression(node.Target)
+		node.Value = h.expression(node.Value)
+	case *ast.CallExpression:
+		node.Function = h.expression(node.Function)
+		h.expressions(node.Arguments)
+	case *ast.IndexExpression:
+		node.Left = h.expression(node.Left)
+		node.Index = h.expression(node.Index)
+	case *ast.SpreadExpression:
+		node.Value = h.expression(node.Value)
+	case *ast.ThrowExpression:
+		node.Value = h.expression(node.Value)
+	case *ast.IfExpression:
+		node.Condition = h.expression(node.Condition)
+		h.block(node.Consequence)
+		h.block(node.Alternative)
+	case *ast.WhileExpression:
+		node.Condition = h.expression(node.Condition)
+		h.block(node.Body)
+	case *ast.ForExpression:
+		node.Collection = h.expression(node.Collection)
+		node.Key = h.binding(node.Key)
+		node.Value = h.binding(node.Value)
+		h.block(node.Body)
+	case *ast.TryExpression:
+		h.block(node.Block)
+		node.CatchParameter = h.binding(node.CatchParameter)
+		h.block(node.Catch)
+		h.block(node.Finally)
+	case *ast.ArrayLiteral:
+		h.expressions(node.Elements)
+	case *ast.HashLiteral:
+		pairs := make(map[ast.Expression]ast.Expression, len(node.Pairs))
+		for key, value := range node.Pairs {
+			pairs[h.expression(key)] = h.expression(value)
+		}
+		node.Pairs = pairs
+	case *ast.FunctionLiteral:
+		h.function(node.Parameters, node.Defaults, &node.Rest, node.Body)
+	case *ast.MacroLiteral:
+		h.function(node.Parameters, nil, &node.Rest, node.Body)
+	}
+
+	return node
+}
+
+func (h *hygiene) function(parameters []*ast.Identifier, defaults []ast.Expression, rest **ast.Identifier, body *ast.BlockStatement) {
+	if h.declaring {
+		return
+	}
+
+	h.enterScope()
+	defer h.leaveScope()
+
+	for _, declaring := range []bool{true, false} {
+		h.declaring = declaring
+
+		for i, parameter := range parameters {
+			parameters[i] = h.binding(parameter)
+		}
+		h.expressions(defaults)
+		*rest = h.binding(*rest)
+		h.block(body)
+	}
+}
+
+func (h *hygiene) binding(identifier *ast.Identifier) *ast.Identifier {
+	if identifier == nil {
+		return nil
+	}
+
+	if !h.declaring {
+		return h.reference(identifier)
+	}
+
+	if _, ok := h.scope.names[identifier.Value]; !ok {
+		if h.fromCallSite[identifier] {
+			h.scope.names[identifier.Value] = ""
+		} else {
+			h.scope.names[identifier.Value] = h.gensym(identifier.Value)
+		}
+	}
+
+	return identifier
+}
+
+func (h *hygiene) reference(identifier *ast.Identifier) *ast.Identifier {
+	if h.declaring || h.fromCallSite[identifier] {
+		return identifier
+	}
+
+	for scope := h.scope; scope != nil; scope = scope.outer {
+		if name, ok := scope.names[identifier.Value]; ok {
+			if name == "" {
+				return identifier
+			}
+
+			return renamedIdentifier(identifier, name)
+		}
+	}
+
+	return identifier
+}
+
+func (h *hygiene) statements(statements []ast.Statement) {
+	for i, statement := range statements {
+		statements[i], _ = h.node(statement).(ast.Statement)
+	}
+}
+
+func (h *hygiene) block(block *ast.BlockStatement) {
+	if block != nil {
+		h.statements(block.Statements)
+	}
+}
+
+func (h *hygiene) expression(expression ast.Expression) ast.Expression {
+	if expression == nil {
+		return nil
+	}
+
+	renamed, _ := h.node(expression).(ast.Expression)
+	return renamed
+}
+
+func (h *hygiene) expressions(expressions []ast.Expression) {
+	for i, expression := range expressions {
+		expressions[i] = h.expression(expression)
+	}
+}
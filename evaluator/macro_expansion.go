@@ -1,40 +1,43 @@
 package evaluator
 
 import (
+	"fmt"
+	"strings"
+
 	"monkeylang/ast"
 	"monkeylang/object"
+	"monkeylang/token"
 )
 
+const DEFAULT_MAX_MACRO_EXPANSION_DEPTH = 1000
+
 func DefineMacros(program *ast.Program, env *object.Environment) {
-	definitions := []int{}
+	program.Statements = defineMacros(program.Statements, env, func(name string, macro *object.Macro) {
+		env.Set(name, macro)
+	})
+}
 
-	for i, stmt := range program.Statements {
-		if isMacroDefinition(stmt) {
-			addMacro(stmt, env)
-			definitions = append(definitions, i)
-		}
-	}
+func defineMacros(stmts []ast.Statement, env *object.Environment, define func(string, *object.Macro)) []ast.Statement {
+	remaining := stmts[:0]
 
-	for i := len(definitions) - 1; i >= 0; i = i - 1 {
-		definitionIndex := definitions[i]
-		program.Statements = append(
-			program.Statements[:definitionIndex],
-			program.Statements[definitionIndex+1:]...,
-		)
-	}
-}
+	for _, stmt := range stmts {
+		if !isMacroDefinition(stmt) {
+			remaining = append(remaining, stmt)
+			continue
+		}
 
-func addMacro(stmt ast.Statement, env *object.Environment) {
-	letStatement, _ := stmt.(*ast.LetStatement)
-	macroLiteral, _ := letStatement.Value.(*ast.MacroLiteral)
+		letStatement, _ := stmt.(*ast.LetStatement)
+		macroLiteral, _ := letStatement.Value.(*ast.MacroLiteral)
 
-	macro := &object.Macro{
-		Parameters: macroLiteral.Parameters,
-		Env:        env,
-		Body:       macroLiteral.Body,
+		define(letStatement.Name.Value, &object.Macro{
+			Parameters: macroLiteral.Parameters,
+			Rest:       macroLiteral.Rest,
+			Env:        env,
+			Body:       macroLiteral.Body,
+		})
 	}
 
-	env.Set(letStatement.Name.Value, macro)
+	return remaining
 }
 
 func isMacroDefinition(node ast.Statement) bool {
@@ -46,35 +49,265 @@ func isMacroDefinition(node ast.Statement) bool {
 
 	_, ok = letStatement.Value.(*ast.MacroLiteral)
 
-	return ok
+	return ok && letStatement.Name != nil
+}
+
+type MacroError struct {
+	Macro    string
+	Message  string
+	Position token.Position
+	Value    object.Object
+}
+
+func (e *MacroError) Error() string {
+	msg := fmt.Sprintf("error expanding macro %s: %s", e.Macro, e.Message)
+	if e.Position.IsValid() {
+		return e.Position.String() + ": " + msg
+	}
+
+	return msg
+}
+
+type MacroErrors []*MacroError
+
+func (e MacroErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+type ExpansionStep struct {
+	Macro    string
+	Position token.Position
+	Before   string
+	After    string
+}
+
+type macroScope struct {
+	macros map[string]*object.Macro
+	outer  *macroScope
+}
+
+func (s *macroScope) get(name string) (*object.Macro, bool) {
+	for ; s != nil; s = s.outer {
+		if macro, ok := s.macros[name]; ok {
+			return macro, true
+		}
+	}
+
+	return nil, false
+}
+
+type MacroExpander struct {
+	env      *object.Environment
+	trace    func(ExpansionStep)
+	hygienic bool
+	maxDepth int
+	scopes   map[*ast.CallExpression]*macroScope
+	failed   map[*ast.CallExpression]bool
+	errors   MacroErrors
+}
+
+func NewMacroExpander(env *object.Environment) *MacroExpander {
+	return &MacroExpander{env: env, maxDepth: DEFAULT_MAX_MACRO_EXPANSION_DEPTH}
+}
+
+func (e *MacroExpander) SetTrace(trace func(ExpansionStep)) {
+	e.trace = trace
+}
+
+func (e *MacroExpander) SetHygienic(hygienic bool) {
+	e.hygienic = hygienic
+}
+
+func (e *MacroExpander) SetMaxDepth(depth int) {
+	e.maxDepth = depth
+}
+
+func ExpandMacros(program ast.Node, env *object.Environment) (ast.Node, MacroErrors) {
+	return NewMacroExpander(env).Expand(program)
+}
+
+func (e *MacroExpander) Expand(program ast.Node) (ast.Node, MacroErrors) {
+	e.errors = nil
+	e.scopes = map[*ast.CallExpression]*macroScope{}
+	e.failed = map[*ast.CallExpression]bool{}
+
+	e.defineScopedMacros(program)
+
+	return e.expand(program, nil, 0), e.errors
 }
 
-func ExpandMacros(program ast.Node, env *object.Environment) ast.Node {
-	return ast.Modify(program, func(node ast.Node) ast.Node {
-		callExpression, ok := node.(*ast.CallExpression)
+func (e *MacroExpander) defineScopedMacros(program ast.Node) {
+	ast.Modify(program, func(node ast.Node) ast.Node {
+		block, ok := node.(*ast.BlockStatement)
 		if !ok {
 			return node
 		}
 
-		macro, ok := isMacroCall(callExpression, env)
-		if !ok {
+		scope := &macroScope{macros: map[string]*object.Macro{}}
+		block.Statements = defineMacros(block.Statements, e.env, func(name string, macro *object.Macro) {
+			scope.macros[name] = macro
+		})
+
+		if len(scope.macros) == 0 {
 			return node
 		}
 
-		args := quoteArgs(callExpression)
-		evalEnv := extendMacroEnv(macro, args)
+		ast.Modify(block, func(node ast.Node) ast.Node {
+			call, ok := node.(*ast.CallExpression)
+			if !ok {
+				return node
+			}
+
+			inner, ok := e.scopes[call]
+			if !ok {
+				e.scopes[call] = scope
+				return node
+			}
+
+			for inner.outer != nil {
+				inner = inner.outer
+			}
 
-		evaluated := Eval(macro.Body, evalEnv)
+			if inner != scope {
+				inner.outer = scope
+			}
 
-		quote, ok := evaluated.(*object.Quote)
+			return node
+		})
+
+		return node
+	})
+}
+
+func (e *MacroExpander) expand(node ast.Node, scope *macroScope, depth int) ast.Node {
+	return ast.Modify(node, func(node ast.Node) ast.Node {
+		call, ok := node.(*ast.CallExpression)
+		if !ok || e.failed[call] {
+			return node
+		}
+
+		callScope, ok := e.scopes[call]
+		if !ok {
+			callScope = scope
+		}
+
+		macro, ok := e.lookup(call, callScope)
 		if !ok {
-			panic("we only support returning AST-nodes from macros")
+			return node
+		}
+
+		if depth >= e.maxDepth {
+			msg := fmt.Sprintf("maximum expansion depth of %d exceeded", e.maxDepth)
+			return e.fail(call, call.Function.String(), msg, nil)
 		}
 
-		return quote.Node
+		expansion := e.expandCall(call, macro)
+		if expansion == ast.Node(call) {
+			return call
+		}
+
+		return e.expand(expansion, callScope, depth+1)
 	})
 }
 
+func (e *MacroExpander) lookup(call *ast.CallExpression, scope *macroScope) (*object.Macro, bool) {
+	identifier, ok := call.Function.(*ast.Identifier)
+	if !ok {
+		return nil, false
+	}
+
+	if macro, ok := scope.get(identifier.Value); ok {
+		return macro, true
+	}
+
+	return isMacroCall(call, e.env)
+}
+
+func (e *MacroExpander) expandCall(call *ast.CallExpression, macro *object.Macro) ast.Node {
+	name := call.Function.String()
+	before := call.String()
+
+	args := quoteArgs(call)
+	if err := macro.CheckArity(len(args)); err != nil {
+		return e.fail(call, name, err.Message, nil)
+	}
+
+	evalEnv := extendMacroEnv(macro, args)
+
+	evaluated := unwrapReturnValue(Eval(macro.Body, evalEnv))
+
+	if err, ok := evaluated.(*object.Error); ok {
+		return e.fail(call, name, err.KindName()+": "+err.Message, err)
+	}
+
+	quote, ok := evaluated.(*object.Quote)
+	if !ok {
+		msg := fmt.Sprintf("macro must return a quoted AST node, got %s", typeOf(evaluated))
+		return e.fail(call, name, msg, evaluated)
+	}
+
+	expansion := quote.Node
+	if e.hygienic {
+		expansion = e.renameBindings(expansion, call)
+	}
+
+	if e.trace != nil {
+		e.trace(ExpansionStep{
+			Macro:    name,
+			Position: call.Pos(),
+			Before:   before,
+			After:    expansion.String(),
+		})
+	}
+
+	return expansion
+}
+
+func (e *MacroExpander) renameBindings(expansion ast.Node, call *ast.CallExpression) ast.Node {
+	fromCallSite := map[ast.Node]bool{}
+	for _, arg := range call.Arguments {
+		ast.Modify(arg, func(node ast.Node) ast.Node {
+			fromCallSite[node] = true
+			return node
+		})
+	}
+
+	h := &hygiene{fromCallSite: fromCallSite, gensym: e.env.Builtins().Gensym}
+	return h.rename(expansion)
+}
+
+func renamedIdentifier(identifier *ast.Identifier, name string) *ast.Identifier {
+	tok := identifier.Token
+	tok.Literal = name
+
+	return &ast.Identifier{Token: tok, Value: name}
+}
+
+func (e *MacroExpander) fail(call *ast.CallExpression, name, msg string, value object.Object) ast.Node {
+	e.failed[call] = true
+	e.errors = append(e.errors, &MacroError{
+		Macro:    name,
+		Message:  msg,
+		Position: call.Pos(),
+		Value:    value,
+	})
+
+	return call
+}
+
+func typeOf(obj object.Object) object.ObjectType {
+	if obj == nil {
+		return object.NULL_OBJ
+	}
+
+	return obj.Type()
+}
+
 func isMacroCall(exp *ast.CallExpression, env *object.Environment) (*object.Macro, bool) {
 	identifier, ok := exp.Function.(*ast.Identifier)
 	if !ok {
@@ -98,6 +331,15 @@ func quoteArgs(exp *ast.CallExpression) []*object.Quote {
 	args := []*object.Quote{}
 
 	for _, arg := range exp.Arguments {
+		if spread, ok := arg.(*ast.SpreadExpression); ok {
+			if array, ok := spread.Value.(*ast.ArrayLiteral); ok {
+				for _, el := range array.Elements {
+					args = append(args, &object.Quote{Node: el})
+				}
+				continue
+			}
+		}
+
 		args = append(args, &object.Quote{Node: arg})
 	}
 
@@ -111,5 +353,14 @@ func extendMacroEnv(macro *object.Macro, args []*object.Quote) *object.Environme
 		extended.Set(param.Value, args[paramIx])
 	}
 
+	if macro.Rest != nil {
+		rest := []object.Object{}
+		for _, arg := range args[len(macro.Parameters):] {
+			rest = append(rest, arg)
+		}
+
+		extended.Set(macro.Rest.Value, &object.Array{Elements: rest})
+	}
+
 	return extended
 }
@@ -5,6 +5,7 @@ import (
 	"monkeylang/lexer"
 	"monkeylang/object"
 	"monkeylang/parser"
+	"monkeylang/token"
 	"testing"
 )
 
@@ -98,6 +99,57 @@ func TestExpandMacros(t *testing.T) {
             `,
 			`if (!(10 > 5)) { puts("not greater") } else { puts("greater") }`,
 		},
+		{
+			`
+            let repeat = macro(cond, body) {
+                quote(while (!(unquote(cond))) { unquote(body); });
+            };
+
+            repeat(x > 3, puts(x));
+            `,
+			`while (!(x > 3)) { puts(x) }`,
+		},
+		{
+			`
+            let double = macro(x) { quote(unquote(x) * 2); };
+
+            for (k, v in xs) { if (v) { break; }; double(v); };
+            while (i > 0) { continue; };
+            `,
+			`for (k, v in xs) { if (v) { break; }; (v * 2) }; while (i > 0) { continue; }`,
+		},
+		{
+			`
+            let exclaim = macro(words) {
+                let out = [];
+                for (word in unquote(words)) { out = push(out, word + "!"); };
+                quote(puts(unquote(out)));
+            };
+
+            exclaim(["a", "b"]);
+            `,
+			`puts(["a!", "b!"])`,
+		},
+		{
+			`
+            let inc = macro(x) { quote(unquote(x) + 1); };
+
+            inc(a);
+            inc(b);
+            `,
+			`(a + 1); (b + 1)`,
+		},
+		{
+			`
+            let twice = macro(x) {
+                let double = fn(n) { let m = n * 2; m };
+                quote(unquote(double)(unquote(x)));
+            };
+
+            twice(a);
+            `,
+			`fn(n) { let m = n * 2; m }(a)`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -106,7 +158,10 @@ func TestExpandMacros(t *testing.T) {
 
 		env := object.NewEnvironment()
 		DefineMacros(program, env)
-		expanded := ExpandMacros(program, env)
+		expanded, errs := ExpandMacros(program, env)
+		if len(errs) != 0 {
+			t.Fatalf("unexpected expansion errors for %q: %v", tt.input, errs)
+		}
 
 		if expanded.String() != expected.String() {
 			t.Errorf("not equal. want=%q, got=%q", expected.String(), expanded.String())
@@ -114,6 +169,484 @@ func TestExpandMacros(t *testing.T) {
 	}
 }
 
+func TestExpandMacrosErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedMacro string
+		expectedError string
+		expectedValue string
+	}{
+		{
+			"let m = macro() { 1 };\nm();",
+			"m",
+			"2:1: error expanding macro m: macro must return a quoted AST node, got INTEGER",
+			"1",
+		},
+		{
+			"let m = macro() { };\nm();",
+			"m",
+			"2:1: error expanding macro m: macro must return a quoted AST node, got NULL",
+			"",
+		},
+		{
+			"let m = macro(x) { 1 + true };\nlet a = m(1);",
+			"m",
+			"2:9: error expanding macro m: TypeError: type mismatch: INTEGER + BOOLEAN",
+			"ERROR: 1:20: type mismatch: INTEGER + BOOLEAN",
+		},
+		{
+			"let m = macro(a, b) { quote(unquote(a)) };\nm(1);",
+			"m",
+			"2:1: error expanding macro m: wrong number of arguments: want=2, got=1",
+			"",
+		},
+		{
+			"let m = macro(a, ...rest) { quote(unquote(a)) };\nm();",
+			"m",
+			"2:1: error expanding macro m: wrong number of arguments: want=1 or more, got=0",
+			"",
+		},
+		{
+			"let m = macro(x) { quote(m(unquote(x))) };\nm(1);",
+			"m",
+			"1:26: error expanding macro m: maximum expansion depth of 1000 exceeded",
+			"",
+		},
+		{
+			"let m = macro() { quote(unquote(len)) };\nm();",
+			"m",
+			"2:1: error expanding macro m: TypeError: cannot unquote value of type BULTIN",
+			"ERROR: 1:19: cannot unquote value of type BULTIN",
+		},
+		{
+			"let m = macro(x) { let f = fn() { x }; quote(unquote(f)()) };\nm(1);",
+			"m",
+			"2:1: error expanding macro m: TypeError: cannot unquote a function that captures local binding x",
+			"ERROR: 1:40: cannot unquote a function that captures local binding x",
+		},
+	}
+
+	for _, tt := range tests {
+		program := testParseProgram(tt.input)
+
+		env := object.NewEnvironment()
+		DefineMacros(program, env)
+		_, errs := ExpandMacros(program, env)
+
+		if len(errs) != 1 {
+			t.Errorf("wrong number of errors for %q. want=1, got=%d (%v)", tt.input, len(errs), errs)
+			continue
+		}
+
+		err := errs[0]
+		if err.Macro != tt.expectedMacro {
+			t.Errorf("wrong macro name. want=%q, got=%q", tt.expectedMacro, err.Macro)
+		}
+
+		if err.Error() != tt.expectedError {
+			t.Errorf("wrong error. want=%q, got=%q", tt.expectedError, err.Error())
+		}
+
+		value := ""
+		if err.Value != nil {
+			value = err.Value.Inspect()
+		}
+
+		if value != tt.expectedValue {
+			t.Errorf("wrong offending value. want=%q, got=%q", tt.expectedValue, value)
+		}
+	}
+}
+
+func TestExpandMacrosKeepsGoingAfterErrors(t *testing.T) {
+	input := `
+    let bad = macro() { 1 };
+    let good = macro(x) { quote(unquote(x) + 1) };
+
+    bad();
+    good(2);
+    `
+
+	program := testParseProgram(input)
+
+	env := object.NewEnvironment()
+	DefineMacros(program, env)
+	expanded, errs := ExpandMacros(program, env)
+
+	if len(errs) != 1 || errs[0].Macro != "bad" {
+		t.Fatalf("expected a single error from bad. got=%v", errs)
+	}
+
+	expected := "bad()(2 + 1)"
+	if expanded.String() != expected {
+		t.Errorf("wrong expansion. want=%q, got=%q", expected, expanded.String())
+	}
+}
+
+func TestMacroExpansionTrace(t *testing.T) {
+	input := `
+    let double = macro(x) { quote(unquote(x) * 2) };
+    let inc = macro(x) { return quote(unquote(x) + 1); };
+
+    double(2);
+    let x = inc(3);
+    `
+
+	program := testParseProgram(input)
+
+	env := object.NewEnvironment()
+	DefineMacros(program, env)
+
+	steps := []ExpansionStep{}
+	expander := NewMacroExpander(env)
+	expander.SetTrace(func(step ExpansionStep) {
+		steps = append(steps, step)
+	})
+
+	expanded, errs := expander.Expand(program)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected expansion errors: %v", errs)
+	}
+
+	if expanded.String() != "(2 * 2)let x = (3 + 1);" {
+		t.Errorf("wrong expansion. got=%q", expanded.String())
+	}
+
+	expected := []ExpansionStep{
+		{Macro: "double", Position: token.Position{Line: 5, Column: 5}, Before: "double(2)", After: "(2 * 2)"},
+		{Macro: "inc", Position: token.Position{Line: 6, Column: 13}, Before: "inc(3)", After: "(3 + 1)"},
+	}
+
+	if len(steps) != len(expected) {
+		t.Fatalf("wrong number of steps. want=%d, got=%d (%+v)", len(expected), len(steps), steps)
+	}
+
+	for i, step := range expected {
+		if steps[i].Macro != step.Macro || steps[i].Before != step.Before || steps[i].After != step.After {
+			t.Errorf("wrong step %d. want=%+v, got=%+v", i, step, steps[i])
+		}
+
+		if steps[i].Position.String() != step.Position.String() {
+			t.Errorf("wrong position for step %d. want=%s, got=%s", i, step.Position, steps[i].Position)
+		}
+	}
+}
+
+func TestHygienicExpandMacros(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			`
+            let swap = macro(a, b) {
+                quote(fn() { let tmp = unquote(a); unquote(a) = unquote(b); unquote(b) = tmp; }());
+            };
+
+            swap(tmp, y);
+            `,
+			`fn()let tmp__1 = tmp;(tmp = y)(y = tmp__1)()`,
+		},
+		{
+			`
+            let each = macro(xs, body) {
+                quote(for (x in unquote(xs)) { unquote(body) });
+            };
+
+            each(x, puts(x));
+            `,
+			`for (x__1 in x) puts(x)`,
+		},
+		{
+			`
+            let safely = macro(body) {
+                quote(try { unquote(body) } catch (e) { fn(e, ...rest) { e }(e) });
+            };
+
+            safely(e);
+            `,
+			`try e catch (e__1) fn(e__2,...rest__3)e__2(e__1)`,
+		},
+		{
+			`
+            let double = macro(x) { quote(unquote(x) * 2) };
+
+            double(x);
+            `,
+			`(x * 2)`,
+		},
+		{
+			`
+            let bind = macro(name, value) { quote(fn() { let v = unquote(value); unquote(name)(v) }()) };
+
+            bind(f, fn(v) { v });
+            `,
+			`fn()let v__1 = fn(v)v;f(v__1)()`,
+		},
+		{
+			`
+            let inc = macro(x) { quote(fn() { let step = 1; unquote(x) + step + offset }()) };
+
+            inc(step);
+            `,
+			`fn()let step__1 = 1;((step + step__1) + offset)()`,
+		},
+		{
+			`
+            let pair = macro() { quote([fn() { let n = 1; n }, fn() { n }]) };
+
+            pair();
+            `,
+			`[fn()let n__1 = 1;n__1, fn()n]`,
+		},
+		{
+			`
+            let parity = macro(x) {
+                quote(fn() {
+                    let isEven = fn(n) { if (n == 0) { true } else { isOdd(n - 1) } };
+                    let isOdd = fn(n) { if (n == 0) { false } else { isEven(n - 1) } };
+                    isEven(unquote(x))
+                }());
+            };
+
+            parity(isOdd);
+            `,
+			`fn()let isEven__1 = fn(n__3)if(n__3 == 0) true else isOdd__2((n__3 - 1));let isOdd__2 = fn(n__4)if(n__4 == 0) false else isEven__1((n__4 - 1));isEven__1(isOdd)()`,
+		},
+	}
+
+	for _, tt := range tests {
+		program := testParseProgram(tt.input)
+
+		env := object.NewEnvironment()
+		DefineMacros(program, env)
+
+		expander := NewMacroExpander(env)
+		expander.SetHygienic(true)
+
+		expanded, errs := expander.Expand(program)
+		if len(errs) != 0 {
+			t.Fatalf("unexpected expansion errors for %q: %v", tt.input, errs)
+		}
+
+		if expanded.String() != tt.expected {
+			t.Errorf("not equal. want=%q, got=%q", tt.expected, expanded.String())
+		}
+	}
+}
+
+func TestGensym(t *testing.T) {
+	input := `
+    let square = macro(value) {
+        let name = gensym("tmp");
+        quote(fn() { let unquote(name) = unquote(value); unquote(name) * unquote(name) }());
+    };
+    let reset = macro(target) {
+        quote(unquote(gensym()) = unquote(target));
+    };
+
+    square(2);
+    square(3);
+    reset(x);
+    `
+
+	program := testParseProgram(input)
+
+	env := object.NewEnvironment()
+	DefineMacros(program, env)
+	expanded, errs := ExpandMacros(program, env)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected expansion errors: %v", errs)
+	}
+
+	expected := `fn()let tmp__1 = 2;(tmp__1 * tmp__1)()fn()let tmp__2 = 3;(tmp__2 * tmp__2)()(g__3 = x)`
+	if expanded.String() != expected {
+		t.Errorf("not equal. want=%q, got=%q", expected, expanded.String())
+	}
+}
+
+func TestRecursiveAndScopedExpandMacros(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			`
+            let double = macro(x) { quote(unquote(x) * 2); };
+            let quadruple = macro(x) { quote(double(double(unquote(x)))); };
+
+            quadruple(y);
+            double(double(z));
+            `,
+			`((y * 2) * 2); ((z * 2) * 2)`,
+		},
+		{
+			`
+            let count = macro(n) {
+                if (unquote(n) == 0) {
+                    quote(0)
+                } else {
+                    let m = unquote(n) - 1;
+                    quote(1 + count(unquote(m)))
+                }
+            };
+
+            count(3);
+            `,
+			`(1 + (1 + (1 + 0)))`,
+		},
+		{
+			`
+            let f = fn(y) {
+                let double = macro(x) { quote(unquote(x) * 2); };
+                double(y);
+            };
+            double(1);
+            `,
+			`let f = fn(y) { (y * 2) }; double(1)`,
+		},
+		{
+			`
+            let m = macro(x) { quote(unquote(x) + 1); };
+            if (true) {
+                let m = macro(x) { quote(unquote(x) - 1); };
+                m(a);
+            };
+            m(b);
+            `,
+			`if (true) { (a - 1) }; (b + 1)`,
+		},
+		{
+			`
+            fn() {
+                let inc = macro(x) { quote(unquote(x) + 1); };
+                if (c) {
+                    let dec = macro(x) { quote(unquote(x) - 1); };
+                    inc(dec(a));
+                }
+            };
+            `,
+			`fn() { if (c) { ((a - 1) + 1) } }`,
+		},
+		{
+			`
+            fn() {
+                let one = macro() { quote(1); };
+                let two = macro() { quote(one() + one()); };
+                two();
+            };
+            `,
+			`fn() { (1 + 1) }`,
+		},
+	}
+
+	for _, tt := range tests {
+		expected := testParseProgram(tt.expected)
+		program := testParseProgram(tt.input)
+
+		env := object.NewEnvironment()
+		DefineMacros(program, env)
+		expanded, errs := ExpandMacros(program, env)
+		if len(errs) != 0 {
+			t.Fatalf("unexpected expansion errors for %q: %v", tt.input, errs)
+		}
+
+		if expanded.String() != expected.String() {
+			t.Errorf("not equal. want=%q, got=%q", expected.String(), expanded.String())
+		}
+	}
+}
+
+func TestVariadicExpandMacros(t *testing.T) {
+	input := `
+    let log = macro(level, ...parts) {
+        quote(puts(unquote(level), ...unquote(parts)));
+    };
+
+    log("info", a, b + c);
+    log("debug");
+    `
+
+	program := testParseProgram(input)
+
+	env := object.NewEnvironment()
+	DefineMacros(program, env)
+	expanded, errs := ExpandMacros(program, env)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected expansion errors: %v", errs)
+	}
+
+	expected := testParseProgram(`puts("info", ...[a, (b + c)]); puts("debug", ...[])`)
+	if expanded.String() != expected.String() {
+		t.Errorf("not equal. want=%q, got=%q", expected.String(), expanded.String())
+	}
+}
+
+func TestExpandMacrosMaxDepth(t *testing.T) {
+	input := `
+    let nest = macro(x) { quote([nest(unquote(x))]); };
+    nest(1);
+    `
+
+	program := testParseProgram(input)
+
+	env := object.NewEnvironment()
+	DefineMacros(program, env)
+
+	expander := NewMacroExpander(env)
+	expander.SetMaxDepth(3)
+
+	_, errs := expander.Expand(program)
+	if len(errs) != 1 {
+		t.Fatalf("wrong number of errors. want=1, got=%d (%v)", len(errs), errs)
+	}
+
+	expected := "maximum expansion depth of 3 exceeded"
+	if errs[0].Message != expected {
+		t.Errorf("wrong error. want=%q, got=%q", expected, errs[0].Message)
+	}
+}
+
+func TestMacrosWithASTBuiltins(t *testing.T) {
+	input := `
+    let flip = macro(e) {
+        if (kind(e) != "InfixExpression") {
+            return e;
+        };
+        let f = fields(e);
+        node("InfixExpression", {"operator": f["operator"], "left": f["right"], "right": f["left"]});
+    };
+    let curry = macro(f) {
+        let params = fields(f)["parameters"];
+        let body = fields(f)["body"];
+        let i = len(params) - 1;
+        while (i >= 0) {
+            body = node("FunctionLiteral", {"parameters": [params[i]], "body": body});
+            i -= 1;
+        };
+        body;
+    };
+
+    flip(a - b);
+    flip(c);
+    curry(fn(x, y) { x + y });
+    `
+
+	program := testParseProgram(input)
+
+	env := object.NewEnvironment()
+	DefineMacros(program, env)
+	expanded, errs := ExpandMacros(program, env)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected expansion errors: %v", errs)
+	}
+
+	expected := "(b - a)cfn(x)fn(y)(x + y)"
+	if expanded.String() != expected {
+		t.Errorf("not equal. want=%q, got=%q", expected, expanded.String())
+	}
+}
+
 func testParseProgram(input string) *ast.Program {
 	l := lexer.New(input)
 	p := parser.New(l)
@@ -8,13 +8,19 @@ import (
 )
 
 func quote(node ast.Node, env *object.Environment) object.Object {
-	node = evalUnquoteCalls(node, env)
+	var err object.Object
+
+	node = evalUnquoteCalls(ast.Copy(node), env, &err)
+	if err != nil {
+		return err
+	}
+
 	return &object.Quote{Node: node}
 }
 
-func evalUnquoteCalls(quoted ast.Node, env *object.Environment) ast.Node {
+func evalUnquoteCalls(quoted ast.Node, env *object.Environment, err *object.Object) ast.Node {
 	return ast.Modify(quoted, func(node ast.Node) ast.Node {
-		if !isUnquoteCall(node) {
+		if *err != nil || !isUnquoteCall(node) {
 			return node
 		}
 
@@ -28,7 +34,18 @@ func evalUnquoteCalls(quoted ast.Node, env *object.Environment) ast.Node {
 		}
 
 		unquoted := Eval(call.Arguments[0], env)
-		return convertObjectToASTNode(unquoted)
+		if isError(unquoted) {
+			*err = unquoted
+			return node
+		}
+
+		converted, convErr := convertObjectToASTNode(unquoted)
+		if convErr != nil {
+			*err = convErr
+			return node
+		}
+
+		return converted
 	})
 }
 
@@ -42,14 +59,38 @@ func isUnquoteCall(node ast.Node) bool {
 	return callExpression.Function.TokenLiteral() == "unquote"
 }
 
-func convertObjectToASTNode(obj object.Object) ast.Node {
+func unquote(node *ast.CallExpression, env *object.Environment) object.Object {
+	if len(node.Arguments) != 1 {
+		return newError(object.ARGUMENT_ERROR, "wrong number of arguments to `unquote`. want=1, got=%d", len(node.Arguments))
+	}
+
+	value := Eval(node.Arguments[0], env)
+	if isError(value) {
+		return value
+	}
+
+	quoted, ok := value.(*object.Quote)
+	if !ok {
+		return value
+	}
+
+	return convertASTNodeToObject(quoted.Node, env)
+}
+
+func convertObjectToASTNode(obj object.Object) (ast.Node, *object.Error) {
 	switch obj := obj.(type) {
 	case *object.Integer:
 		t := token.Token{
 			Type:    token.INT,
 			Literal: fmt.Sprintf("%d", obj.Value),
 		}
-		return &ast.IntegerLiteral{Token: t, Value: obj.Value}
+		return &ast.IntegerLiteral{Token: t, Value: obj.Value}, nil
+	case *object.Float:
+		t := token.Token{
+			Type:    token.FLOAT,
+			Literal: obj.Inspect(),
+		}
+		return &ast.FloatLiteral{Token: t, Value: obj.Value}, nil
 	case *object.Boolean:
 		var t token.Token
 		if obj.Value {
@@ -58,10 +99,149 @@ func convertObjectToASTNode(obj object.Object) ast.Node {
 			t = token.Token{Type: token.FALSE, Literal: "false"}
 		}
 
-		return &ast.Boolean{Token: t, Value: obj.Value}
+		return &ast.Boolean{Token: t, Value: obj.Value}, nil
+	case *object.String:
+		t := token.Token{Type: token.STRING, Literal: obj.Value}
+		return &ast.StringLiteral{Token: t, Value: obj.Value}, nil
+	case *object.Null:
+		return &ast.Null{Token: token.Token{Type: token.NULL, Literal: "null"}}, nil
+	case *object.Array:
+		elements := make([]ast.Expression, 0, len(obj.Elements))
+		for _, el := range obj.Elements {
+			node, err := convertObjectToASTExpression(el)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, node)
+		}
+
+		t := token.Token{Type: token.LBRACKET, Literal: "["}
+		end := token.Token{Type: token.RBRACKET, Literal: "]"}
+		return &ast.ArrayLiteral{Token: t, Elements: elements, EndToken: end}, nil
+	case *object.Hash:
+		pairs := make(map[ast.Expression]ast.Expression, len(obj.Pairs))
+		for _, pair := range obj.SortedPairs() {
+			key, err := convertObjectToASTExpression(pair.Key)
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := convertObjectToASTExpression(pair.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			pairs[key] = value
+		}
+
+		t := token.Token{Type: token.LBRACE, Literal: "{"}
+		end := token.Token{Type: token.RBRACE, Literal: "}"}
+		return &ast.HashLiteral{Token: t, Pairs: pairs, EndToken: end}, nil
+	case *object.Function:
+		literal := &ast.FunctionLiteral{
+			Token:      token.Token{Type: token.FUNCTION, Literal: "fn"},
+			Name:       obj.Name,
+			Parameters: obj.Parameters,
+			Defaults:   obj.Defaults,
+			Rest:       obj.Rest,
+			Body:       obj.Body,
+		}
+
+		for _, name := range freeIdentifiersOf(literal) {
+			if obj.Env != nil && obj.Env.IsLocal(name) {
+				return nil, newError(object.TYPE_ERROR, "cannot unquote a function that captures local binding %s", name)
+			}
+		}
+
+		return ast.Copy(literal), nil
 	case *object.Quote:
-		return obj.Node
+		return obj.Node, nil
 	default:
-		return nil
+		return nil, newError(object.TYPE_ERROR, "cannot unquote value of type %s", typeOf(obj))
 	}
 }
+
+func convertObjectToASTExpression(obj object.Object) (ast.Expression, *object.Error) {
+	node, err := convertObjectToASTNode(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	exp, ok := node.(ast.Expression)
+	if !ok {
+		return nil, newError(object.TYPE_ERROR, "cannot use %s as an expression", node.String())
+	}
+
+	return exp, nil
+}
+
+func convertASTNodeToObject(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	case *ast.ExpressionStatement:
+		return convertASTNodeToObject(node.Expression, env)
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+	case *ast.Boolean:
+		return nativeBoolToBoolean(node.Value)
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+	case *ast.Null:
+		return NULL
+	case *ast.PrefixExpression:
+		if node.Operator == "-" {
+			switch right := node.Right.(type) {
+			case *ast.IntegerLiteral:
+				return &object.Integer{Value: -right.Value}
+			case *ast.FloatLiteral:
+				return &object.Float{Value: -right.Value}
+			}
+		}
+	case *ast.ArrayLiteral:
+		elements := make([]object.Object, 0, len(node.Elements))
+		for _, el := range node.Elements {
+			value := convertASTNodeToObject(el, env)
+			if isError(value) {
+				return value
+			}
+			elements = append(elements, value)
+		}
+
+		return &object.Array{Elements: elements}
+	case *ast.HashLiteral:
+		pairs := make(map[object.HashKey]object.HashPair, len(node.Pairs))
+		for keyNode, valueNode := range node.Pairs {
+			key := convertASTNodeToObject(keyNode, env)
+			if isError(key) {
+				return key
+			}
+
+			hashKey, ok := key.(object.Hashable)
+			if !ok {
+				return newError(object.TYPE_ERROR, "unusable as a hash key: %s", key.Type())
+			}
+
+			value := convertASTNodeToObject(valueNode, env)
+			if isError(value) {
+				return value
+			}
+
+			pairs[hashKey.HashKey()] = object.HashPair{Key: key, Value: value}
+		}
+
+		return &object.Hash{Pairs: pairs}
+	case *ast.FunctionLiteral:
+		literal := ast.Copy(node).(*ast.FunctionLiteral)
+		return &object.Function{
+			Name:       literal.Name,
+			Parameters: literal.Parameters,
+			Defaults:   literal.Defaults,
+			Rest:       literal.Rest,
+			Body:       literal.Body,
+			Env:        env,
+		}
+	}
+
+	return newError(object.TYPE_ERROR, "cannot convert %s to a value", node.String())
+}
@@ -3,10 +3,13 @@ package evaluator
 import (
 	"testing"
 
+	"monkeylang/ast"
 	"monkeylang/object"
 )
 
 func TestQuote(t *testing.T) {
+	skipUnlessTreeWalking(t)
+
 	tests := []struct {
 		input    string
 		expected string
@@ -35,6 +38,8 @@ func TestQuote(t *testing.T) {
 }
 
 func TestQuoteUnquote(t *testing.T) {
+	skipUnlessTreeWalking(t)
+
 	tests := []struct {
 		input    string
 		expected string
@@ -70,6 +75,31 @@ func TestQuoteUnquote(t *testing.T) {
             quote(unquote(4 + 4) + unquote(quotedInfixExpression))`,
 			`(8 + (4 + 4))`,
 		},
+		{
+			`quote(unquote("monkey"))`,
+			`monkey`,
+		},
+		{
+			`quote(unquote(null))`,
+			`null`,
+		},
+		{
+			`quote(unquote([1, "two", [true, null]]))`,
+			`[1, two, [true, null]]`,
+		},
+		{
+			`quote(unquote({"a": [1, 2]}))`,
+			`{a: [1, 2]}`,
+		},
+		{
+			`let double = fn(x) { x * 2 };
+            quote(unquote(double)(2))`,
+			`fn(x)(x * 2)(2)`,
+		},
+		{
+			`quote(unquote([quote(a + b), 1.5]))`,
+			`[(a + b), 1.5]`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -88,3 +118,241 @@ func TestQuoteUnquote(t *testing.T) {
 		}
 	}
 }
+
+func TestQuoteUnquoteErrors(t *testing.T) {
+	skipUnlessTreeWalking(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`quote(unquote(len))`, "cannot unquote value of type BULTIN"},
+		{`quote(1 + unquote([1, len]))`, "cannot unquote value of type BULTIN"},
+		{`quote(unquote(1 + true))`, "type mismatch: INTEGER + BOOLEAN"},
+		{
+			`let adder = fn(n) { fn(x) { x + n } };
+            quote(unquote(adder(1))(2))`,
+			"cannot unquote a function that captures local binding n",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("expected *object.Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if err.Message != tt.expected {
+			t.Errorf("wrong error message. want=%q, got=%q", tt.expected, err.Message)
+		}
+	}
+}
+
+func TestUnquotedFunctionIsCopied(t *testing.T) {
+	skipUnlessTreeWalking(t)
+
+	evaluated := testEval(`let double = fn(x) { x * 2 }; double`)
+	fn, ok := evaluated.(*object.Function)
+	if !ok {
+		t.Fatalf("expected *object.Function. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	node, err := convertObjectToASTNode(fn)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Message)
+	}
+
+	literal, ok := node.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("expected *ast.FunctionLiteral. got=%T", node)
+	}
+
+	if literal.Body == fn.Body || literal.Parameters[0] == fn.Parameters[0] {
+		t.Errorf("unquoted function shares AST nodes with the live function")
+	}
+
+	if literal.String() != "fn(x)(x * 2)" {
+		t.Errorf("wrong unquoted function. got=%q", literal.String())
+	}
+}
+
+func TestQuotedFunctionIsCopied(t *testing.T) {
+	skipUnlessTreeWalking(t)
+
+	quoted, ok := testEval(`quote(fn(x) { x * 2 })`).(*object.Quote)
+	if !ok {
+		t.Fatalf("expected *object.Quote")
+	}
+
+	fn, ok := convertASTNodeToObject(quoted.Node, object.NewEnvironment()).(*object.Function)
+	if !ok {
+		t.Fatalf("expected *object.Function")
+	}
+
+	literal := quoted.Node.(*ast.FunctionLiteral)
+	literal.Parameters[0].Value = "y"
+	literal.Body.Statements = nil
+
+	if fn.Inspect() != "fn(x) {\n(x * 2)\n}" {
+		t.Errorf("converted function changed with its quote. got=%q", fn.Inspect())
+	}
+}
+
+func TestUnquoteQuotedData(t *testing.T) {
+	skipUnlessTreeWalking(t)
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`unquote(quote(5))`, 5},
+		{`unquote(quote(-2.5))`, -2.5},
+		{`unquote(quote("monkey"))`, "monkey"},
+		{`unquote(quote([1, -2, 3]))`, []int64{1, -2, 3}},
+		{`unquote(quote({"a": 1}))["a"]`, 1},
+		{`unquote(quote(fn(x) { x * 2 }))(4)`, 8},
+		{`unquote(7)`, 7},
+		{`len(unquote(quote(["a", "b"])))`, 2},
+		{`unquote(quote(a + b))`, "cannot convert (a + b) to a value"},
+		{`unquote(quote([1, x]))`, "cannot convert x to a value"},
+		{`unquote(quote({[1]: 2}))`, "unusable as a hash key: ARRAY"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestQuotedNodeInspection(t *testing.T) {
+	skipUnlessTreeWalking(t)
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`kind(quote(a + b))`, "InfixExpression"},
+		{`kind(quote(fn(x) { x }))`, "FunctionLiteral"},
+		{`fields(quote(a + b))["operator"]`, "+"},
+		{`kind(fields(quote(a + b))["left"])`, "Identifier"},
+		{`fields(fields(quote(a + b))["right"])["name"]`, "b"},
+		{`fields(quote(42))["value"]`, 42},
+		{`fields(quote("monkey"))["value"]`, "monkey"},
+		{`len(fields(quote(fn(x, y = 1, ...z) { x }))["parameters"])`, 2},
+		{`fields(fields(quote(fn(x, y = 1, ...z) { x }))["rest"])["name"]`, "z"},
+		{`fields(quote(fn(x, y = 1) { x }))["defaults"][0]`, nil},
+		{`fields(fields(quote(fn(x, y = 1) { x }))["defaults"][1])["value"]`, 1},
+		{`len(fields(quote(f(1, 2, 3)))["arguments"])`, 3},
+		{`fields(quote(if (x) { y }))["alternative"]`, nil},
+		{`kind(fields(quote(if (x) { y }))["consequence"])`, "BlockStatement"},
+		{`len(fields(quote({"a": 1, "b": 2}))["pairs"])`, 2},
+		{`fields(fields(quote({"a": 1}))["pairs"][0][1])["value"]`, 1},
+		{`kind(1)`, "argument to `kind` must be QUOTE. got INTEGER"},
+		{`fields("a")`, "argument to `fields` must be QUOTE. got STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestQuotedNodeConstruction(t *testing.T) {
+	skipUnlessTreeWalking(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`node("IntegerLiteral", {"value": 5})`, `5`},
+		{`node("Identifier", {"name": "x"})`, `x`},
+		{`node("Identifier", fields(gensym("tmp")))`, `tmp__1`},
+		{`node("InfixExpression", {"operator": "*", "left": quote(a), "right": quote(2)})`, `(a * 2)`},
+		{`node("CallExpression", {"function": quote(f), "arguments": [quote(1), quote(x)]})`, `f(1, x)`},
+		{`node("ArrayLiteral", {"elements": [quote(1), node("Null")]})`, `[1, null]`},
+		{`node("IfExpression", {"condition": quote(x), "consequence": quote(y)})`, `ifx y`},
+		{
+			`node("FunctionLiteral", {"parameters": [quote(x)], "body": quote(x + 1)})`,
+			`fn(x)(x + 1)`,
+		},
+		{
+			`node("LetStatement", {"name": quote(total), "value": quote(1 + 2)})`,
+			`let total = (1 + 2);`,
+		},
+		{
+			`let q = quote(fn(a, b = 2, ...c) { a + b });
+            node(kind(q), fields(q))`,
+			`fn(a,b = 2,...c)(a + b)`,
+		},
+		{
+			`let q = quote(x - y);
+            let f = fields(q);
+            node(kind(q), {"operator": f["operator"], "left": f["right"], "right": f["left"]})`,
+			`(y - x)`,
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		quote, ok := evaluated.(*object.Quote)
+		if !ok {
+			t.Errorf("expected *object.Quote for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if quote.Node.String() != tt.expected {
+			t.Errorf("not equal. got=%q, want=%q", quote.Node.String(), tt.expected)
+		}
+	}
+}
+
+func TestQuotedNodeConstructionErrors(t *testing.T) {
+	skipUnlessTreeWalking(t)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`node("Nope", {})`, "unknown node kind: Nope"},
+		{`node(1, {})`, "first argument to `node` must be STRING. got INTEGER"},
+		{`node("Identifier", 1)`, "second argument to `node` must be HASH. got INTEGER"},
+		{`node("Identifier", {})`, `missing field "name" for Identifier`},
+		{`node("Identifier", {"name": ""})`, `invalid identifier name for Identifier: ""`},
+		{`node("Identifier", {"name": "1a"})`, `invalid identifier name for Identifier: "1a"`},
+		{`node("Identifier", {"name": "a b"})`, `invalid identifier name for Identifier: "a b"`},
+		{`node("Identifier", {"name": "let"})`, `invalid identifier name for Identifier: "let"`},
+		{`node("InfixExpression", {"operator": "^", "left": quote(a), "right": quote(b)})`, "invalid operator for InfixExpression: ^"},
+		{`node("PrefixExpression", {"operator": "-", "right": 1})`, `field "right" of PrefixExpression must be QUOTE, got INTEGER`},
+		{`node("LetStatement", {"name": quote(1), "value": quote(2)})`, `field "name" of LetStatement must be an Identifier, got IntegerLiteral`},
+		{`node("TryExpression", {"block": quote(x)})`, "TryExpression needs a catch or a finally block"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("expected *object.Error for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if err.Message != tt.expected {
+			t.Errorf("wrong error message. want=%q, got=%q", tt.expected, err.Message)
+		}
+	}
+}
+
+func TestQuotedNodeConstructionInvalidIdentifier(t *testing.T) {
+	skipUnlessTreeWalking(t)
+
+	evaluated := testEval(`node("Identifier", {"name": "a b"})`)
+	err, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("expected *object.Error. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if err.Kind != object.TYPE_ERROR {
+		t.Errorf("wrong error kind. want=%s, got=%s", object.TYPE_ERROR, err.Kind)
+	}
+}
@@ -0,0 +1,156 @@
+package evaluator
+
+import (
+	"monkeylang/ast"
+	"monkeylang/object"
+)
+
+const TAIL_CALL_OBJ = "TAIL_CALL"
+
+type tailCall struct {
+	call *ast.CallExpression
+	fn   object.Object
+	args []object.Object
+}
+
+func (tc *tailCall) Type() object.ObjectType { return TAIL_CALL_OBJ }
+func (tc *tailCall) Inspect() string         { return "tail call" }
+
+func evalTail(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	case *ast.BlockStatement:
+		return evalTailBlockStatement(node, env)
+	case *ast.ExpressionStatement:
+		return evalTail(node.Expression, env)
+	case *ast.ReturnStatement:
+		val := evalTail(node.ReturnValue, env)
+		if isError(val) || isTailCall(val) {
+			return val
+		}
+		return &object.ReturnValue{Value: val}
+	case *ast.IfExpression:
+		return evalTailIfExpression(node, env)
+	case *ast.InfixExpression:
+		if node.Operator != "&&" && node.Operator != "||" {
+			return Eval(node, env)
+		}
+
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+
+		if (node.Operator == "&&") != isTruthy(left) {
+			return left
+		}
+
+		return evalTail(node.Right, env)
+	case *ast.CallExpression:
+		if name := node.Function.TokenLiteral(); name == "quote" || name == "unquote" {
+			return Eval(node, env)
+		}
+
+		function := Eval(node.Function, env)
+		if isError(function) {
+			return function
+		}
+
+		args := evalArguments(node.Arguments, env)
+		if len(args) == 1 && isError(args[0]) {
+			return args[0]
+		}
+
+		return &tailCall{call: node, fn: function, args: args}
+	default:
+		return Eval(node, env)
+	}
+}
+
+func evalTailBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	var result object.Object
+
+	for i, statement := range block.Statements {
+		if i == len(block.Statements)-1 {
+			return evalTail(statement, env)
+		}
+
+		result = evalTailReturns(statement, env)
+		if result != nil && (isControlFlow(result) || isTailCall(result)) {
+			return result
+		}
+	}
+
+	return result
+}
+
+func evalTailIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
+	condition := Eval(ie.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+
+	if isTruthy(condition) {
+		return evalTail(ie.Consequence, env)
+	} else if ie.Alternative != nil {
+		return evalTail(ie.Alternative, env)
+	} else {
+		return NULL
+	}
+}
+
+func evalTailReturns(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	case *ast.ReturnStatement:
+		return evalTail(node, env)
+	case *ast.BlockStatement:
+		var result object.Object
+
+		for _, statement := range node.Statements {
+			result = evalTailReturns(statement, env)
+			if result != nil && (isControlFlow(result) || isTailCall(result)) {
+				return result
+			}
+		}
+
+		return result
+	case *ast.ExpressionStatement:
+		ie, ok := node.Expression.(*ast.IfExpression)
+		if !ok {
+			return Eval(node, env)
+		}
+
+		condition := Eval(ie.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+
+		if isTruthy(condition) {
+			return evalTailReturns(ie.Consequence, env)
+		} else if ie.Alternative != nil {
+			return evalTailReturns(ie.Alternative, env)
+		} else {
+			return NULL
+		}
+	default:
+		return Eval(node, env)
+	}
+}
+
+func isTailCall(obj object.Object) bool {
+	_, ok := obj.(*tailCall)
+	return ok
+}
+
+func applyTailCall(tc *tailCall, env *object.Environment) object.Object {
+	result := applyFunction(tc.call, tc.fn, tc.args)
+
+	if _, ok := tc.fn.(*object.Builtin); ok {
+		result = allocate(env, result)
+	}
+
+	if err, ok := result.(*object.Error); ok && !err.Position.IsValid() {
+		err.Position = tc.call.Pos()
+	}
+
+	return result
+}
@@ -7,19 +7,40 @@ type Lexer struct {
 	position     int
 	readPosition int
 	char         byte
+
+	file   string
+	line   int
+	column int
+
+	previous token.TokenType
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewFile("", input)
+}
+
+func NewFile(file string, input string) *Lexer {
+	l := &Lexer{input: input, file: file, line: 1}
 	l.readChar()
+	l.skipShebang()
 	return l
 }
 
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
-
 	l.skipWhitespace()
 
+	start := l.currentPosition()
+	tok := l.readToken()
+	tok.Start = start
+	tok.End = l.currentPosition()
+	l.previous = tok.Type
+
+	return tok
+}
+
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
+
 	switch l.char {
 	case '=':
 		if l.peekChar() == '=' {
@@ -48,27 +69,43 @@ func (l *Lexer) NextToken() token.Token {
 	case ',':
 		tok = newToken(token.COMMA, l.char)
 	case '+':
-		tok = newToken(token.PLUS, l.char)
+		tok = l.readOperator('=', token.PLUS, token.PLUS_ASSIGN)
 	case '{':
 		tok = newToken(token.LBRACE, l.char)
 	case '}':
 		tok = newToken(token.RBRACE, l.char)
 	case '-':
-		tok = newToken(token.MINUS, l.char)
+		tok = l.readOperator('=', token.MINUS, token.MINUS_ASSIGN)
 	case '/':
-		tok = newToken(token.SLASH, l.char)
+		tok = l.readOperator('=', token.SLASH, token.SLASH_ASSIGN)
 	case '*':
-		tok = newToken(token.ASTERISK, l.char)
+		tok = l.readOperator('=', token.ASTERISK, token.ASTERISK_ASSIGN)
+	case '%':
+		tok = l.readOperator('=', token.PERCENT, token.PERCENT_ASSIGN)
 	case '<':
-		tok = newToken(token.LT, l.char)
+		tok = l.readOperator('=', token.LT, token.LT_EQ)
 	case '>':
-		tok = newToken(token.GT, l.char)
+		tok = l.readOperator('=', token.GT, token.GT_EQ)
+	case '&':
+		tok = l.readOperator('&', token.ILLEGAL, token.AND)
+	case '|':
+		tok = l.readOperator('|', token.ILLEGAL, token.OR)
 	case '[':
 		tok = newToken(token.LBRACKET, l.char)
 	case ']':
 		tok = newToken(token.RBRACKET, l.char)
 	case ':':
 		tok = newToken(token.COLON, l.char)
+	case '.':
+		if l.peekChar() == '.' && l.peekCharAt(2) == '.' {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else if isDigit(l.peekChar()) && !l.followsOperand() {
+			return l.readNumber()
+		} else {
+			tok = newToken(token.ILLEGAL, l.char)
+		}
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
@@ -81,10 +118,7 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Type = token.LookupIdentifier(tok.Literal)
 			return tok
 		} else if isDigit(l.char) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
-			return tok
-
+			return l.readNumber()
 		} else {
 			tok = newToken(token.ILLEGAL, l.char)
 		}
@@ -94,7 +128,23 @@ func (l *Lexer) NextToken() token.Token {
 	return tok
 }
 
+func (l *Lexer) readOperator(next byte, single, double token.TokenType) token.Token {
+	if l.peekChar() != next {
+		return newToken(single, l.char)
+	}
+
+	char := l.char
+	l.readChar()
+
+	return token.Token{Type: double, Literal: string(char) + string(l.char)}
+}
+
 func (l *Lexer) readChar() {
+	if l.char == '\n' {
+		l.line += 1
+		l.column = 0
+	}
+
 	if l.readPosition >= len(l.input) {
 		l.char = 0
 	} else {
@@ -103,6 +153,16 @@ func (l *Lexer) readChar() {
 
 	l.position = l.readPosition
 	l.readPosition += 1
+	l.column += 1
+}
+
+func (l *Lexer) currentPosition() token.Position {
+	return token.Position{
+		File:   l.file,
+		Line:   l.line,
+		Column: l.column,
+		Offset: l.position,
+	}
 }
 
 func (l *Lexer) readIdentifier() string {
@@ -115,14 +175,60 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[startingPosition:l.position]
 }
 
-func (l *Lexer) readNumber() string {
+func (l *Lexer) readNumber() token.Token {
 	startingPosition := l.position
+	tokenType := token.TokenType(token.INT)
 
-	for isDigit(l.char) {
+	l.readDigits()
+
+	if l.char == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
 		l.readChar()
+		l.readDigits()
 	}
 
-	return l.input[startingPosition:l.position]
+	if l.char == 'e' || l.char == 'E' {
+		next := l.peekChar()
+		if isDigit(next) || (next == '+' || next == '-') && isDigit(l.peekCharAt(2)) {
+			tokenType = token.FLOAT
+			l.readChar()
+			if l.char == '+' || l.char == '-' {
+				l.readChar()
+			}
+			l.readDigits()
+		}
+	}
+
+	if isLetter(l.char) || isDigit(l.char) || l.char == '.' {
+		tokenType = token.ILLEGAL
+		l.readMalformedNumber()
+	}
+
+	return token.Token{Type: tokenType, Literal: l.input[startingPosition:l.position]}
+}
+
+func (l *Lexer) readMalformedNumber() {
+	for isLetter(l.char) || isDigit(l.char) || l.char == '.' {
+		if (l.char == 'e' || l.char == 'E') && (l.peekChar() == '+' || l.peekChar() == '-') {
+			l.readChar()
+		}
+		l.readChar()
+	}
+}
+
+func (l *Lexer) followsOperand() bool {
+	switch l.previous {
+	case token.IDENT, token.INT, token.FLOAT, token.STRING, token.RPAREN, token.RBRACKET:
+		return true
+	}
+
+	return false
+}
+
+func (l *Lexer) readDigits() {
+	for isDigit(l.char) {
+		l.readChar()
+	}
 }
 
 func (l *Lexer) readString() string {
@@ -139,11 +245,26 @@ func (l *Lexer) readString() string {
 }
 
 func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
+	return l.peekCharAt(1)
+}
+
+func (l *Lexer) peekCharAt(distance int) byte {
+	position := l.position + distance
+	if position >= len(l.input) {
 		return 0
 	}
 
-	return l.input[l.readPosition]
+	return l.input[position]
+}
+
+func (l *Lexer) skipShebang() {
+	if l.char != '#' || l.peekChar() != '!' {
+		return
+	}
+
+	for l.char != '\n' && l.char != 0 {
+		l.readChar()
+	}
 }
 
 func (l *Lexer) skipWhitespace() {
@@ -205,3 +205,235 @@ macro(x, y) { x + y };
 		}
 	}
 }
+
+func TestNextTokenPositions(t *testing.T) {
+	input := `let x = 5;
+  "a b" + foo;
+`
+
+	tests := []struct {
+		expectedLiteral string
+		expectedStart   token.Position
+		expectedEnd     token.Position
+	}{
+		{"let", token.Position{File: "test.mk", Line: 1, Column: 1, Offset: 0}, token.Position{File: "test.mk", Line: 1, Column: 4, Offset: 3}},
+		{"x", token.Position{File: "test.mk", Line: 1, Column: 5, Offset: 4}, token.Position{File: "test.mk", Line: 1, Column: 6, Offset: 5}},
+		{"=", token.Position{File: "test.mk", Line: 1, Column: 7, Offset: 6}, token.Position{File: "test.mk", Line: 1, Column: 8, Offset: 7}},
+		{"5", token.Position{File: "test.mk", Line: 1, Column: 9, Offset: 8}, token.Position{File: "test.mk", Line: 1, Column: 10, Offset: 9}},
+		{";", token.Position{File: "test.mk", Line: 1, Column: 10, Offset: 9}, token.Position{File: "test.mk", Line: 1, Column: 11, Offset: 10}},
+		{"a b", token.Position{File: "test.mk", Line: 2, Column: 3, Offset: 13}, token.Position{File: "test.mk", Line: 2, Column: 8, Offset: 18}},
+		{"+", token.Position{File: "test.mk", Line: 2, Column: 9, Offset: 19}, token.Position{File: "test.mk", Line: 2, Column: 10, Offset: 20}},
+		{"foo", token.Position{File: "test.mk", Line: 2, Column: 11, Offset: 21}, token.Position{File: "test.mk", Line: 2, Column: 14, Offset: 24}},
+		{";", token.Position{File: "test.mk", Line: 2, Column: 14, Offset: 24}, token.Position{File: "test.mk", Line: 2, Column: 15, Offset: 25}},
+		{"", token.Position{File: "test.mk", Line: 3, Column: 1, Offset: 26}, token.Position{File: "test.mk", Line: 3, Column: 2, Offset: 27}},
+	}
+
+	l := NewFile("test.mk", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong -- expected %q, got %q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Start != tt.expectedStart {
+			t.Errorf("tests[%d] - start wrong -- expected %+v, got %+v", i, tt.expectedStart, tok.Start)
+		}
+
+		if tok.End != tt.expectedEnd {
+			t.Errorf("tests[%d] - end wrong -- expected %+v, got %+v", i, tt.expectedEnd, tok.End)
+		}
+	}
+}
+
+func TestShebangIsSkipped(t *testing.T) {
+	input := "#!/usr/bin/env monkeylang\nlet x = 1;"
+
+	l := New(input)
+
+	tok := l.NextToken()
+	if tok.Type != token.LET {
+		t.Fatalf("tokentype wrong -- expected %q, got %q", token.LET, tok.Type)
+	}
+
+	if tok.Start.Line != 2 || tok.Start.Column != 1 {
+		t.Errorf("position wrong -- expected 2:1, got %s", tok.Start)
+	}
+}
+
+func TestNextTokenNumbers(t *testing.T) {
+	input := `.5 5 3.14 1e-9 2E+3 7e2 1.5e3 1.foo 3e x.5 3.14.15 1e3.5 a[0].5 (.25) ...rest ..`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FLOAT, ".5"},
+		{token.INT, "5"},
+		{token.FLOAT, "3.14"},
+		{token.FLOAT, "1e-9"},
+		{token.FLOAT, "2E+3"},
+		{token.FLOAT, "7e2"},
+		{token.FLOAT, "1.5e3"},
+		{token.ILLEGAL, "1.foo"},
+		{token.ILLEGAL, "3e"},
+		{token.IDENT, "x"},
+		{token.ILLEGAL, "."},
+		{token.INT, "5"},
+		{token.ILLEGAL, "3.14.15"},
+		{token.ILLEGAL, "1e3.5"},
+		{token.IDENT, "a"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.ILLEGAL, "."},
+		{token.INT, "5"},
+		{token.LPAREN, "("},
+		{token.FLOAT, ".25"},
+		{token.RPAREN, ")"},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.ILLEGAL, "."},
+		{token.ILLEGAL, "."},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong -- expected %q, got %q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong -- expected %q, got %q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenMalformedNumbers(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+		next            token.TokenType
+	}{
+		{"1e", "1e", token.EOF},
+		{"1e+", "1e+", token.EOF},
+		{"1e5e3", "1e5e3", token.EOF},
+		{"1.", "1.", token.EOF},
+		{"1..2", "1..2", token.EOF},
+		{"12abc)", "12abc", token.RPAREN},
+		{".5x + 1", ".5x", token.PLUS},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+
+		tok := l.NextToken()
+		if tok.Type != token.ILLEGAL || tok.Literal != tt.expectedLiteral {
+			t.Errorf("wrong token for %q. want=ILLEGAL %q, got=%s %q", tt.input, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+
+		if next := l.NextToken(); next.Type != tt.next {
+			t.Errorf("wrong token after %q. want=%s, got=%s %q", tt.input, tt.next, next.Type, next.Literal)
+		}
+	}
+}
+
+func TestNextTokenAssignmentOperators(t *testing.T) {
+	input := `x += 1; x -= 2; x *= 3; x /= 4; x %= 5; x % 6; a[0] = b`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "x"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.SLASH_ASSIGN, "/="},
+		{token.INT, "4"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.PERCENT_ASSIGN, "%="},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.PERCENT, "%"},
+		{token.INT, "6"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "b"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong -- expected %q, got %q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong -- expected %q, got %q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextTokenLogicalOperators(t *testing.T) {
+	input := `a <= b >= c && d || e < f & g | h`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.LT_EQ, "<="},
+		{token.IDENT, "b"},
+		{token.GT_EQ, ">="},
+		{token.IDENT, "c"},
+		{token.AND, "&&"},
+		{token.IDENT, "d"},
+		{token.OR, "||"},
+		{token.IDENT, "e"},
+		{token.LT, "<"},
+		{token.IDENT, "f"},
+		{token.ILLEGAL, "&"},
+		{token.IDENT, "g"},
+		{token.ILLEGAL, "|"},
+		{token.IDENT, "h"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong -- expected %q, got %q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong -- expected %q, got %q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
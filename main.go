@@ -1,10 +1,16 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"os/user"
 
+	"monkeylang/engine"
+	"monkeylang/evaluator"
+	"monkeylang/lexer"
+	"monkeylang/object"
+	"monkeylang/parser"
 	"monkeylang/repl"
 )
 
@@ -21,7 +27,146 @@ const MONKEY_FACE = `            __,__
            '-----'
 `
 
+const USAGE = `Usage:
+  monkeylang [flags] [FILE]
+  monkeylang repl [flags]
+  monkeylang run [flags] FILE
+  monkeylang eval [flags] EXPRESSION
+
+Without a command, monkeylang runs FILE when one is given and starts the REPL
+otherwise.
+
+Flags:
+`
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
 func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := newFlagSet("monkeylang")
+	expression := flags.String("e", "", "evaluate `EXPRESSION` and print its result")
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "repl":
+			return replCommand(args[1:])
+		case "run":
+			return runCommand(args[1:])
+		case "eval":
+			return evalCommand(args[1:])
+		case "help":
+			flags.SetOutput(os.Stdout)
+			flags.Usage()
+			return exitOK
+		}
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	e, ok := newEngine(flags)
+	if !ok {
+		return exitUsage
+	}
+
+	switch {
+	case *expression != "":
+		return evalSource(*expression, e)
+	case flags.NArg() > 0:
+		return runFile(flags.Arg(0), e)
+	default:
+		return startRepl(e)
+	}
+}
+
+func replCommand(args []string) int {
+	flags := newFlagSet("repl")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	e, ok := newEngine(flags)
+	if !ok {
+		return exitUsage
+	}
+
+	return startRepl(e)
+}
+
+func runCommand(args []string) int {
+	flags := newFlagSet("run")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	e, ok := newEngine(flags)
+	if !ok {
+		return exitUsage
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "run: expected exactly one FILE argument")
+		return exitUsage
+	}
+
+	return runFile(flags.Arg(0), e)
+}
+
+func evalCommand(args []string) int {
+	flags := newFlagSet("eval")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	e, ok := newEngine(flags)
+	if !ok {
+		return exitUsage
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "eval: expected exactly one EXPRESSION argument")
+		return exitUsage
+	}
+
+	return evalSource(flags.Arg(0), e)
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.String("backend", string(engine.EvaluatorBackend), "execution `BACKEND`: eval (tree-walking evaluator) or vm (bytecode virtual machine)")
+	flags.Bool("hygienic", false, "rename bindings introduced by macro expansions so they cannot capture names at the call site")
+	flags.Usage = func() { printUsage(flags) }
+
+	return flags
+}
+
+func printUsage(flags *flag.FlagSet) {
+	fmt.Fprint(flags.Output(), USAGE)
+	flags.PrintDefaults()
+}
+
+func newEngine(flags *flag.FlagSet) (*engine.Engine, bool) {
+	backend, err := engine.ParseBackend(flags.Lookup("backend").Value.String())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, false
+	}
+
+	e := engine.New(backend)
+	e.SetHygienicMacros(flags.Lookup("hygienic").Value.String() == "true")
+
+	return e, true
+}
+
+func startRepl(e *engine.Engine) int {
 	user, err := user.Current()
 
 	if err != nil {
@@ -30,5 +175,65 @@ func main() {
 	fmt.Print(MONKEY_FACE)
 	fmt.Printf("\nHello %s! This is the Monkey programming language.\n", user.Username)
 	fmt.Printf("Feel free to type in commands! To exit, type in: .exit\n\n")
-	repl.Start(os.Stdin, os.Stdout)
+	repl.StartEngine(os.Stdin, os.Stdout, e)
+
+	return exitOK
+}
+
+func runFile(path string, e *engine.Engine) int {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	result, ok := execute(path, string(source), e)
+	if !ok {
+		return exitError
+	}
+
+	return reportError(result)
+}
+
+func evalSource(source string, e *engine.Engine) int {
+	result, ok := execute("<eval>", source, e)
+	if !ok {
+		return exitError
+	}
+
+	if code := reportError(result); code != exitOK {
+		return code
+	}
+
+	if result != nil && result != evaluator.NULL {
+		fmt.Println(result.Inspect())
+	}
+
+	return exitOK
+}
+
+func execute(name, source string, e *engine.Engine) (object.Object, bool) {
+	l := lexer.NewFile(name, source)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		for _, err := range p.Errors() {
+			fmt.Fprintln(os.Stderr, err)
+		}
+
+		return nil, false
+	}
+
+	return e.Execute(program), true
+}
+
+func reportError(result object.Object) int {
+	errObj, ok := result.(*object.Error)
+	if !ok {
+		return exitOK
+	}
+
+	fmt.Fprintln(os.Stderr, errObj.Traceback())
+	return exitError
 }
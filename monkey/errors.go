@@ -0,0 +1,90 @@
+package monkey
+
+import (
+	"fmt"
+	"strings"
+
+	"monkeylang/evaluator"
+	"monkeylang/object"
+	"monkeylang/parser"
+)
+
+type ParseError struct {
+	File   string
+	Errors []*parser.Error
+}
+
+func (e *ParseError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+type MacroError struct {
+	File   string
+	Errors evaluator.MacroErrors
+}
+
+func (e *MacroError) Error() string {
+	return e.Errors.Error()
+}
+
+type RuntimeError struct {
+	Err *object.Error
+}
+
+func (e *RuntimeError) Error() string {
+	return formatError(e.Err)
+}
+
+func (e *RuntimeError) Traceback() string {
+	return e.Err.Traceback()
+}
+
+type LimitError struct {
+	Err *object.Error
+}
+
+func (e *LimitError) Error() string {
+	return formatError(e.Err)
+}
+
+func (e *LimitError) Traceback() string {
+	return e.Err.Traceback()
+}
+
+func formatError(err *object.Error) string {
+	if err.Position.IsValid() {
+		return err.Position.String() + ": " + err.KindName() + ": " + err.Message
+	}
+
+	return err.KindName() + ": " + err.Message
+}
+
+type UndefinedError struct {
+	Name string
+}
+
+func (e *UndefinedError) Error() string {
+	return "identifier not found: " + e.Name
+}
+
+type ConversionError struct {
+	Path    string
+	Message string
+}
+
+func (e *ConversionError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+
+	return e.Path + ": " + e.Message
+}
+
+func conversionErrorf(path string, format string, a ...interface{}) error {
+	return &ConversionError{Path: path, Message: fmt.Sprintf(format, a...)}
+}
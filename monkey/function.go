@@ -0,0 +1,137 @@
+package monkey
+
+import (
+	"fmt"
+	"reflect"
+
+	"monkeylang/object"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func (i *Interpreter) Register(name string, fn interface{}) error {
+	builtin, err := NewBuiltin(name, fn)
+	if err != nil {
+		return err
+	}
+
+	i.engine.Builtins().Define(name, builtin)
+
+	return nil
+}
+
+func (i *Interpreter) Unregister(name string) bool {
+	return i.engine.Builtins().Remove(name)
+}
+
+func (i *Interpreter) Builtins() []string {
+	return i.engine.Builtins().Names()
+}
+
+func NewBuiltin(name string, fn interface{}) (*object.Builtin, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("cannot register %s: %T is not a function", name, fn)
+	}
+
+	t := v.Type()
+
+	switch {
+	case t.NumOut() > 2:
+		return nil, fmt.Errorf("cannot register %s: %s returns more than two values", name, t)
+	case t.NumOut() == 2 && t.Out(1) != errorType:
+		return nil, fmt.Errorf("cannot register %s: second result of %s must be error", name, t)
+	}
+
+	return &object.Builtin{Fn: func(args ...object.Object) object.Object {
+		return callFunc(name, v, args)
+	}}, nil
+}
+
+func callFunc(name string, fn reflect.Value, args []object.Object) (result object.Object) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = &object.Error{Kind: object.ERROR, Message: fmt.Sprintf("panic in `%s`: %v", name, r)}
+		}
+	}()
+
+	in, err := funcArguments(name, fn.Type(), args)
+	if err != nil {
+		return err
+	}
+
+	return funcResult(name, fn.Call(in))
+}
+
+func funcArguments(name string, t reflect.Type, args []object.Object) ([]reflect.Value, *object.Error) {
+	numIn := t.NumIn()
+
+	if t.IsVariadic() && len(args) < numIn-1 {
+		return nil, &object.Error{
+			Kind:    object.ARGUMENT_ERROR,
+			Message: fmt.Sprintf("wrong number of arguments to `%s`. want>=%d, got=%d", name, numIn-1, len(args)),
+		}
+	}
+
+	if !t.IsVariadic() && len(args) != numIn {
+		return nil, &object.Error{
+			Kind:    object.ARGUMENT_ERROR,
+			Message: fmt.Sprintf("wrong number of arguments to `%s`. want=%d, got=%d", name, numIn, len(args)),
+		}
+	}
+
+	in := make([]reflect.Value, len(args))
+
+	for i, arg := range args {
+		paramType := parameterType(t, i)
+
+		value := reflect.New(paramType).Elem()
+		if err := decode(arg, value, "", visited{}); err != nil {
+			return nil, &object.Error{
+				Kind:    object.TYPE_ERROR,
+				Message: fmt.Sprintf("argument %d to `%s`: %s", i+1, name, err),
+			}
+		}
+
+		in[i] = value
+	}
+
+	return in, nil
+}
+
+func parameterType(t reflect.Type, i int) reflect.Type {
+	if t.IsVariadic() && i >= t.NumIn()-1 {
+		return t.In(t.NumIn() - 1).Elem()
+	}
+
+	return t.In(i)
+}
+
+func funcResult(name string, out []reflect.Value) object.Object {
+	if len(out) == 0 {
+		return object.NULL
+	}
+
+	last := out[len(out)-1]
+	if last.Type() == errorType {
+		if !last.IsNil() {
+			return &object.Error{Kind: object.ERROR, Message: last.Interface().(error).Error()}
+		}
+
+		out = out[:len(out)-1]
+	}
+
+	if len(out) == 0 {
+		return object.NULL
+	}
+
+	obj, err := toObject(out[0], "", visited{})
+	if err != nil {
+		return &object.Error{
+			Kind:    object.TYPE_ERROR,
+			Message: fmt.Sprintf("result of `%s`: %s", name, err),
+		}
+	}
+
+	return obj
+}
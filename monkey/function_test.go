@@ -0,0 +1,216 @@
+package monkey
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"monkeylang/object"
+)
+
+func TestRegister(t *testing.T) {
+	for _, backend := range backends {
+		interp := NewWithBackend(backend)
+
+		var logged []string
+
+		functions := map[string]interface{}{
+			"repeat": func(s string, count int) (string, error) {
+				if count < 0 {
+					return "", fmt.Errorf("count must not be negative, got %d", count)
+				}
+				return strings.Repeat(s, count), nil
+			},
+			"join": func(sep string, parts ...string) string {
+				return strings.Join(parts, sep)
+			},
+			"log": func(msg string) {
+				logged = append(logged, msg)
+			},
+			"address": func(s server) string {
+				return fmt.Sprintf("%s:%d", s.Host, s.Port)
+			},
+			"lookup": func(host string) *server {
+				if host == "" {
+					return nil
+				}
+				return &server{Host: host, Port: 443}
+			},
+			"kind": func(obj object.Object) string {
+				return string(obj.Type())
+			},
+			"check": func(ok bool) error {
+				if !ok {
+					return errors.New("check failed")
+				}
+				return nil
+			},
+			"explode": func() int {
+				panic("boom")
+			},
+		}
+
+		for name, fn := range functions {
+			if err := interp.Register(name, fn); err != nil {
+				t.Fatalf("[%s] Register(%s) returned error: %s", backend, name, err)
+			}
+		}
+
+		tests := []struct {
+			input    string
+			expected interface{}
+		}{
+			{`repeat("ab", 2)`, "abab"},
+			{`join(", ")`, ""},
+			{`join("-", "a", "b", "c")`, "a-b-c"},
+			{`log("one"); log("two")`, nil},
+			{`address({"host": "example.com", "port": 8080})`, "example.com:8080"},
+			{`lookup("example.com")["port"]`, int64(443)},
+			{`lookup("")`, nil},
+			{`kind(fn(x) { x })`, "FUNCTION"},
+			{`check(true)`, nil},
+			{`try { repeat("a", -1) } catch (e) { e["message"] }`, "count must not be negative, got -1"},
+			{`let f = fn(xs) { xs[0] }; repeat(f(["x"]), 3)`, "xxx"},
+		}
+
+		for _, tt := range tests {
+			result, err := interp.Run(tt.input)
+			if err != nil {
+				t.Errorf("[%s] Run(%q) returned error: %s", backend, tt.input, err)
+				continue
+			}
+
+			value, err := FromObject(result)
+			if err != nil {
+				t.Errorf("[%s] FromObject returned error: %s", backend, err)
+				continue
+			}
+
+			if value != tt.expected {
+				t.Errorf("[%s] Run(%q) wrong result. want=%#v, got=%#v", backend, tt.input, tt.expected, value)
+			}
+		}
+
+		if strings.Join(logged, ",") != "one,two" {
+			t.Errorf("[%s] wrong log. got=%q", backend, logged)
+		}
+
+		errorTests := []struct {
+			input    string
+			expected string
+		}{
+			{`repeat("a", -1)`, "Error: count must not be negative, got -1"},
+			{`repeat("a")`, "ArgumentError: wrong number of arguments to `repeat`. want=2, got=1"},
+			{`join()`, "ArgumentError: wrong number of arguments to `join`. want>=1, got=0"},
+			{`repeat(1, 2)`, "TypeError: argument 1 to `repeat`: cannot decode INTEGER into string"},
+			{`join("-", "a", 3)`, "TypeError: argument 3 to `join`: cannot decode INTEGER into string"},
+			{`address({"port": "x"})`, "TypeError: argument 1 to `address`: port: cannot decode STRING into int"},
+			{`check(false)`, "Error: check failed"},
+			{`explode()`, "Error: panic in `explode`: boom"},
+		}
+
+		for _, tt := range errorTests {
+			_, err := interp.Run(tt.input)
+
+			var runtimeErr *RuntimeError
+			if !errors.As(err, &runtimeErr) {
+				t.Errorf("[%s] Run(%q) expected *RuntimeError, got=%T (%v)", backend, tt.input, err, err)
+				continue
+			}
+
+			if got := runtimeErr.Err.KindName() + ": " + runtimeErr.Err.Message; got != tt.expected {
+				t.Errorf("[%s] Run(%q) wrong error. want=%q, got=%q", backend, tt.input, tt.expected, got)
+			}
+		}
+
+		result, err := interp.Call("repeat", "z", 3)
+		if err != nil {
+			t.Fatalf("[%s] Call(repeat) returned error: %s", backend, err)
+		}
+		if str, ok := result.(*object.String); !ok || str.Value != "zzz" {
+			t.Errorf("[%s] Call(repeat) wrong result. got=%T (%+v)", backend, result, result)
+		}
+
+		if _, err := NewWithBackend(backend).Run(`repeat("a", 1)`); err == nil {
+			t.Errorf("[%s] registered function leaked into another interpreter", backend)
+		}
+	}
+}
+
+func TestRegisterErrors(t *testing.T) {
+	tests := []struct {
+		fn       interface{}
+		expected string
+	}{
+		{42, "cannot register f: int is not a function"},
+		{(func())(nil), "cannot register f: func() is not a function"},
+		{func() (int, int, error) { return 0, 0, nil }, "cannot register f: func() (int, int, error) returns more than two values"},
+		{func() (int, string) { return 0, "" }, "cannot register f: second result of func() (int, string) must be error"},
+	}
+
+	for _, tt := range tests {
+		err := New().Register("f", tt.fn)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("Register(%T) wrong error. want=%q, got=%v", tt.fn, tt.expected, err)
+		}
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	for _, backend := range backends {
+		interp := NewWithBackend(backend)
+
+		if err := interp.Register("len", func(s string) int { return -len(s) }); err != nil {
+			t.Fatalf("[%s] Register(len) returned error: %s", backend, err)
+		}
+
+		obj, err := interp.Run(`len("abc")`)
+		if err != nil {
+			t.Fatalf("[%s] Run returned error: %s", backend, err)
+		}
+		testInteger(t, backend, obj, -3)
+
+		if !interp.Unregister("puts") {
+			t.Errorf("[%s] Unregister(puts) returned false", backend)
+		}
+		if interp.Unregister("puts") {
+			t.Errorf("[%s] Unregister(puts) returned true twice", backend)
+		}
+
+		for _, name := range interp.Builtins() {
+			if name == "puts" {
+				t.Errorf("[%s] Builtins() lists unregistered puts", backend)
+			}
+		}
+
+		if _, err := interp.Run(`puts("hi")`); err == nil {
+			t.Errorf("[%s] unregistered builtin still callable", backend)
+		}
+	}
+}
+
+func TestNewWithBuiltins(t *testing.T) {
+	for _, backend := range backends {
+		builtins := object.NewEmptyBuiltinRegistry()
+		interp := NewWithBuiltins(backend, builtins)
+
+		if err := interp.Register("double", func(n int) int { return n * 2 }); err != nil {
+			t.Fatalf("[%s] Register(double) returned error: %s", backend, err)
+		}
+
+		if names := interp.Builtins(); len(names) != 1 || names[0] != "double" {
+			t.Errorf("[%s] wrong builtins. got=%v", backend, names)
+		}
+
+		obj, err := interp.Run(`double(21)`)
+		if err != nil {
+			t.Fatalf("[%s] Run returned error: %s", backend, err)
+		}
+		testInteger(t, backend, obj, 42)
+
+		if _, err := interp.Run(`len("abc")`); err == nil {
+			t.Errorf("[%s] len available in an empty registry", backend)
+		}
+	}
+}
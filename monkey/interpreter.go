@@ -0,0 +1,150 @@
+package monkey
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"monkeylang/engine"
+	"monkeylang/lexer"
+	"monkeylang/object"
+	"monkeylang/parser"
+)
+
+type Interpreter struct {
+	engine *engine.Engine
+}
+
+func New() *Interpreter {
+	return NewWithBackend(engine.EvaluatorBackend)
+}
+
+func NewWithBackend(backend engine.Backend) *Interpreter {
+	return &Interpreter{engine: engine.New(backend)}
+}
+
+func NewWithBuiltins(backend engine.Backend, builtins *object.BuiltinRegistry) *Interpreter {
+	return &Interpreter{engine: engine.NewWithBuiltins(backend, builtins)}
+}
+
+func (i *Interpreter) SetStdout(w io.Writer) {
+	i.engine.Builtins().SetStdout(w)
+}
+
+func (i *Interpreter) SetStderr(w io.Writer) {
+	i.engine.Builtins().SetStderr(w)
+}
+
+func (i *Interpreter) SetStdin(r io.Reader) {
+	i.engine.Builtins().SetStdin(r)
+}
+
+func (i *Interpreter) SetLimits(limits object.Limits) {
+	i.engine.SetLimits(limits)
+}
+
+func (i *Interpreter) SetMaxRecursionDepth(depth int) {
+	i.engine.SetMaxRecursionDepth(depth)
+}
+
+func (i *Interpreter) SetHygienicMacros(hygienic bool) {
+	i.engine.SetHygienicMacros(hygienic)
+}
+
+func (i *Interpreter) Run(source string) (object.Object, error) {
+	return i.RunContext(context.Background(), source)
+}
+
+func (i *Interpreter) RunContext(ctx context.Context, source string) (object.Object, error) {
+	return i.run(ctx, "", source)
+}
+
+func (i *Interpreter) RunFile(path string) (object.Object, error) {
+	return i.RunFileContext(context.Background(), path)
+}
+
+func (i *Interpreter) RunFileContext(ctx context.Context, path string) (object.Object, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.run(ctx, path, string(source))
+}
+
+func (i *Interpreter) Call(name string, args ...interface{}) (object.Object, error) {
+	return i.CallContext(context.Background(), name, args...)
+}
+
+func (i *Interpreter) CallContext(ctx context.Context, name string, args ...interface{}) (object.Object, error) {
+	fn, ok := i.engine.Get(name)
+	if !ok {
+		return nil, &UndefinedError{Name: name}
+	}
+
+	objects := make([]object.Object, len(args))
+	for idx, arg := range args {
+		obj, err := ToObject(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d to %s: %w", idx, name, err)
+		}
+
+		objects[idx] = obj
+	}
+
+	return result(i.engine.CallContext(ctx, fn, objects))
+}
+
+func (i *Interpreter) Set(name string, value interface{}) error {
+	obj, err := ToObject(value)
+	if err != nil {
+		return fmt.Errorf("cannot set %s: %w", name, err)
+	}
+
+	i.engine.Set(name, obj)
+
+	return nil
+}
+
+func (i *Interpreter) Get(name string) (object.Object, error) {
+	value, ok := i.engine.Get(name)
+	if !ok {
+		return nil, &UndefinedError{Name: name}
+	}
+
+	return value, nil
+}
+
+func (i *Interpreter) run(ctx context.Context, file, source string) (object.Object, error) {
+	l := lexer.NewFile(file, source)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		return nil, &ParseError{File: file, Errors: p.Errors()}
+	}
+
+	expanded, errs := i.engine.Expand(program)
+	if len(errs) != 0 {
+		return nil, &MacroError{File: file, Errors: errs}
+	}
+
+	return result(i.engine.ExecuteExpanded(ctx, expanded))
+}
+
+func result(obj object.Object) (object.Object, error) {
+	if err, ok := obj.(*object.Error); ok {
+		if err.Kind == object.LIMIT_ERROR {
+			return nil, &LimitError{Err: err}
+		}
+
+		return nil, &RuntimeError{Err: err}
+	}
+
+	if obj == nil {
+		return object.NULL, nil
+	}
+
+	return obj, nil
+}
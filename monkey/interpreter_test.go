@@ -0,0 +1,417 @@
+package monkey
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"monkeylang/engine"
+	"monkeylang/object"
+	"monkeylang/parser"
+)
+
+var backends = []engine.Backend{engine.EvaluatorBackend, engine.VMBackend}
+
+func TestRunKeepsState(t *testing.T) {
+	for _, backend := range backends {
+		interp := NewWithBackend(backend)
+
+		inputs := []string{
+			`let unless = macro(cond, cons, alt) { quote(if (!(unquote(cond))) { unquote(cons) } else { unquote(alt) }) };`,
+			`let double = fn(x) { x * 2 };`,
+			`unless(10 > 5, 0, double(21))`,
+		}
+
+		var result object.Object
+		for _, input := range inputs {
+			var err error
+			result, err = interp.Run(input)
+			if err != nil {
+				t.Fatalf("[%s] Run(%q) returned error: %s", backend, input, err)
+			}
+		}
+
+		testInteger(t, backend, result, 42)
+	}
+}
+
+func TestRunReturnsNullForStatements(t *testing.T) {
+	for _, backend := range backends {
+		result, err := NewWithBackend(backend).Run("let x = 1;")
+		if err != nil {
+			t.Fatalf("[%s] unexpected error: %s", backend, err)
+		}
+
+		if result != object.NULL {
+			t.Errorf("[%s] result is not NULL. got=%T (%+v)", backend, result, result)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, backend := range backends {
+		interp := NewWithBackend(backend)
+
+		_, err := interp.Run("let x = ;")
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("[%s] expected *ParseError, got=%T (%v)", backend, err, err)
+		}
+
+		if len(parseErr.Errors) == 0 {
+			t.Errorf("[%s] ParseError has no messages", backend)
+		} else if parseErr.Errors[0].Code != parser.MISSING_EXPRESSION {
+			t.Errorf("[%s] wrong parse error code. got=%s", backend, parseErr.Errors[0].Code)
+		}
+
+		_, err = interp.Run("let f = fn() { 1 + true };\nf()")
+		var runtimeErr *RuntimeError
+		if !errors.As(err, &runtimeErr) {
+			t.Fatalf("[%s] expected *RuntimeError, got=%T (%v)", backend, err, err)
+		}
+
+		expected := "1:16: TypeError: type mismatch: INTEGER + BOOLEAN"
+		if err.Error() != expected {
+			t.Errorf("[%s] wrong error message. want=%q, got=%q", backend, expected, err.Error())
+		}
+
+		if len(runtimeErr.Err.Stack) != 1 {
+			t.Errorf("[%s] wrong stack length. want=1, got=%d", backend, len(runtimeErr.Err.Stack))
+		}
+
+		_, err = interp.Run("let broken = macro() { 1 };\nlet angry = macro() { throw \"no\" };\nbroken();\nangry();")
+		var macroErr *MacroError
+		if !errors.As(err, &macroErr) {
+			t.Fatalf("[%s] expected *MacroError, got=%T (%v)", backend, err, err)
+		}
+
+		expected = "3:1: error expanding macro broken: macro must return a quoted AST node, got INTEGER\n" +
+			"4:1: error expanding macro angry: Error: no"
+		if err.Error() != expected {
+			t.Errorf("[%s] wrong error message. want=%q, got=%q", backend, expected, err.Error())
+		}
+
+		if len(macroErr.Errors) != 2 {
+			t.Fatalf("[%s] wrong number of macro errors. want=2, got=%d", backend, len(macroErr.Errors))
+		}
+
+		if value := macroErr.Errors[0].Value; value == nil || value.Inspect() != "1" {
+			t.Errorf("[%s] wrong value for the first macro error. got=%v", backend, value)
+		}
+
+		thrown, ok := macroErr.Errors[1].Value.(*object.Error)
+		if !ok || thrown.Value == nil || thrown.Value.Inspect() != "no" {
+			t.Errorf("[%s] wrong thrown value for the second macro error. got=%v", backend, macroErr.Errors[1].Value)
+		}
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.monkey")
+	if err := os.WriteFile(path, []byte("let x = 20;\nx + 1 + true"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, backend := range backends {
+		interp := NewWithBackend(backend)
+
+		_, err := interp.RunFile(path)
+		var runtimeErr *RuntimeError
+		if !errors.As(err, &runtimeErr) {
+			t.Fatalf("[%s] expected *RuntimeError, got=%T (%v)", backend, err, err)
+		}
+
+		if runtimeErr.Err.Position.File != path {
+			t.Errorf("[%s] wrong error file. want=%q, got=%q", backend, path, runtimeErr.Err.Position.File)
+		}
+
+		x, err := interp.Get("x")
+		if err != nil {
+			t.Fatalf("[%s] Get(x) returned error: %s", backend, err)
+		}
+		testInteger(t, backend, x, 20)
+
+		if _, err := interp.RunFile(filepath.Join(t.TempDir(), "missing.monkey")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("[%s] expected os.ErrNotExist, got=%v", backend, err)
+		}
+	}
+}
+
+func TestSetAndGet(t *testing.T) {
+	for _, backend := range backends {
+		interp := NewWithBackend(backend)
+
+		if err := interp.Set("base", 40); err != nil {
+			t.Fatalf("[%s] Set returned error: %s", backend, err)
+		}
+		if err := interp.Set("name", "monkey"); err != nil {
+			t.Fatalf("[%s] Set returned error: %s", backend, err)
+		}
+
+		result, err := interp.Run(`let total = base + 2; if (name == "monkey") { total } else { 0 }`)
+		if err != nil {
+			t.Fatalf("[%s] Run returned error: %s", backend, err)
+		}
+		testInteger(t, backend, result, 42)
+
+		total, err := interp.Get("total")
+		if err != nil {
+			t.Fatalf("[%s] Get returned error: %s", backend, err)
+		}
+		testInteger(t, backend, total, 42)
+
+		if err := interp.Set("base", 1); err != nil {
+			t.Fatalf("[%s] Set returned error: %s", backend, err)
+		}
+		result, err = interp.Run(`base`)
+		if err != nil {
+			t.Fatalf("[%s] Run returned error: %s", backend, err)
+		}
+		testInteger(t, backend, result, 1)
+
+		_, err = interp.Get("missing")
+		var undefinedErr *UndefinedError
+		if !errors.As(err, &undefinedErr) || undefinedErr.Name != "missing" {
+			t.Errorf("[%s] expected *UndefinedError for missing, got=%T (%v)", backend, err, err)
+		}
+
+		if err := interp.Set("ch", make(chan int)); err == nil {
+			t.Errorf("[%s] expected error setting a channel", backend)
+		}
+	}
+}
+
+func TestCall(t *testing.T) {
+	for _, backend := range backends {
+		interp := NewWithBackend(backend)
+
+		_, err := interp.Run(`
+			let count = 0;
+			let add = fn(a, b) { count += 1; a + b };
+			let fail = fn(x) { x + true };
+			let answer = 42;
+		`)
+		if err != nil {
+			t.Fatalf("[%s] Run returned error: %s", backend, err)
+		}
+
+		result, err := interp.Call("add", 40, 2)
+		if err != nil {
+			t.Fatalf("[%s] Call(add) returned error: %s", backend, err)
+		}
+		testInteger(t, backend, result, 42)
+
+		result, err = interp.Call("add", "a", "b")
+		if err != nil {
+			t.Fatalf("[%s] Call(add) returned error: %s", backend, err)
+		}
+		if str, ok := result.(*object.String); !ok || str.Value != "ab" {
+			t.Errorf("[%s] wrong result. got=%T (%+v)", backend, result, result)
+		}
+
+		count, err := interp.Get("count")
+		if err != nil {
+			t.Fatalf("[%s] Get(count) returned error: %s", backend, err)
+		}
+		testInteger(t, backend, count, 2)
+
+		result, err = interp.Call("len", "four")
+		if err != nil {
+			t.Fatalf("[%s] Call(len) returned error: %s", backend, err)
+		}
+		testInteger(t, backend, result, 4)
+
+		tests := []struct {
+			name     string
+			args     []interface{}
+			expected string
+		}{
+			{"fail", []interface{}{1}, "TypeError: type mismatch: INTEGER + BOOLEAN"},
+			{"add", []interface{}{1}, "ArgumentError: wrong number of arguments: want=2, got=1"},
+			{"add", []interface{}{1, 2, 3}, "ArgumentError: wrong number of arguments: want=2, got=3"},
+			{"answer", nil, "TypeError: not a function: INTEGER"},
+		}
+
+		for _, tt := range tests {
+			_, err := interp.Call(tt.name, tt.args...)
+			var runtimeErr *RuntimeError
+			if !errors.As(err, &runtimeErr) {
+				t.Errorf("[%s] Call(%s): expected *RuntimeError, got=%T (%v)", backend, tt.name, err, err)
+				continue
+			}
+
+			if tt.expected != runtimeErr.Err.KindName()+": "+runtimeErr.Err.Message {
+				t.Errorf("[%s] Call(%s): wrong error. want=%q, got=%q", backend, tt.name, tt.expected, err)
+			}
+		}
+
+		_, err = interp.Call("missing")
+		var undefinedErr *UndefinedError
+		if !errors.As(err, &undefinedErr) {
+			t.Errorf("[%s] expected *UndefinedError, got=%T (%v)", backend, err, err)
+		}
+
+		if _, err := interp.Call("add", 1, struct{}{}); err == nil {
+			t.Errorf("[%s] expected conversion error", backend)
+		}
+	}
+}
+
+func TestInterpretersAreIsolated(t *testing.T) {
+	for _, backend := range backends {
+		first := NewWithBackend(backend)
+		second := NewWithBackend(backend)
+
+		if _, err := first.Run("let shared = 1;"); err != nil {
+			t.Fatalf("[%s] Run returned error: %s", backend, err)
+		}
+
+		if _, err := second.Get("shared"); err == nil {
+			t.Errorf("[%s] global leaked between interpreters", backend)
+		}
+	}
+}
+
+func testInteger(t *testing.T, backend engine.Backend, obj object.Object, expected int64) {
+	t.Helper()
+
+	integer, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf("[%s] object is not Integer. got=%T (%+v)", backend, obj, obj)
+		return
+	}
+
+	if integer.Value != expected {
+		t.Errorf("[%s] wrong value. want=%d, got=%d", backend, expected, integer.Value)
+	}
+}
+
+func TestRedirectIO(t *testing.T) {
+	for _, backend := range backends {
+		interp := NewWithBackend(backend)
+
+		var stdout, stderr bytes.Buffer
+		interp.SetStdout(&stdout)
+		interp.SetStderr(&stderr)
+		interp.SetStdin(strings.NewReader("Ada\n"))
+
+		_, err := interp.Run(`let name = readline("name? "); print("hello,", name); eprint("done"); puts(1, 2)`)
+		if err != nil {
+			t.Fatalf("[%s] Run returned error: %s", backend, err)
+		}
+
+		if stdout.String() != "name? hello, Ada\n1\n2\n" {
+			t.Errorf("[%s] wrong stdout. got=%q", backend, stdout.String())
+		}
+
+		if stderr.String() != "done\n" {
+			t.Errorf("[%s] wrong stderr. got=%q", backend, stderr.String())
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	for _, backend := range backends {
+		interp := NewWithBackend(backend)
+		interp.SetLimits(object.Limits{MaxSteps: 1000})
+
+		if _, err := interp.Run(`let spin = fn() { while (true) {} }; let x = 1;`); err != nil {
+			t.Fatalf("[%s] Run returned error: %s", backend, err)
+		}
+
+		_, err := interp.Run(`spin()`)
+
+		var limitErr *LimitError
+		if !errors.As(err, &limitErr) {
+			t.Fatalf("[%s] expected *LimitError, got=%T (%v)", backend, err, err)
+		}
+
+		if limitErr.Err.Message != "step limit exceeded: 1000 steps" {
+			t.Errorf("[%s] wrong message. got=%q", backend, limitErr.Err.Message)
+		}
+
+		if _, err := interp.Call("spin"); !errors.As(err, &limitErr) {
+			t.Errorf("[%s] Call: expected *LimitError, got=%T (%v)", backend, err, err)
+		}
+
+		obj, err := interp.Run(`x + 1`)
+		if err != nil {
+			t.Fatalf("[%s] Run after limit returned error: %s", backend, err)
+		}
+		testInteger(t, backend, obj, 2)
+
+		interp.SetLimits(object.Limits{})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		_, err = interp.RunContext(ctx, `spin()`)
+		cancel()
+
+		if !errors.As(err, &limitErr) {
+			t.Fatalf("[%s] RunContext: expected *LimitError, got=%T (%v)", backend, err, err)
+		}
+
+		if limitErr.Err.Message != "execution canceled: context deadline exceeded" {
+			t.Errorf("[%s] wrong message. got=%q", backend, limitErr.Err.Message)
+		}
+	}
+}
+
+func TestSetMaxRecursionDepth(t *testing.T) {
+	for _, backend := range backends {
+		interp := NewWithBackend(backend)
+		interp.SetMaxRecursionDepth(100)
+
+		if _, err := interp.Run(`let depth = fn(n) { if (n == 0) { 0 } else { 1 + depth(n - 1) } };`); err != nil {
+			t.Fatalf("[%s] Run returned error: %s", backend, err)
+		}
+
+		obj, err := interp.Call("depth", 99)
+		if err != nil {
+			t.Fatalf("[%s] depth(99) returned error: %s", backend, err)
+		}
+		testInteger(t, backend, obj, 99)
+
+		_, err = interp.Run(`depth(100)`)
+
+		var runtimeErr *RuntimeError
+		if !errors.As(err, &runtimeErr) {
+			t.Fatalf("[%s] expected *RuntimeError, got=%T (%v)", backend, err, err)
+		}
+
+		if runtimeErr.Err.Kind != object.RECURSION_ERROR {
+			t.Errorf("[%s] wrong error kind. got=%s", backend, runtimeErr.Err.Kind)
+		}
+	}
+}
+
+func TestSetHygienicMacros(t *testing.T) {
+	input := `
+let twice = macro(x) { quote(if (true) { let tmp = unquote(x) * 2; tmp }) };
+let tmp = 10;
+let doubled = twice(tmp);
+tmp
+`
+
+	for _, backend := range backends {
+		for _, hygienic := range []bool{false, true} {
+			interp := NewWithBackend(backend)
+			interp.SetHygienicMacros(hygienic)
+
+			obj, err := interp.Run(input)
+			if err != nil {
+				t.Fatalf("[%s] Run returned error: %s", backend, err)
+			}
+
+			expected := int64(20)
+			if hygienic {
+				expected = 10
+			}
+			testInteger(t, backend, obj, expected)
+		}
+	}
+}
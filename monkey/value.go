@@ -0,0 +1,481 @@
+package monkey
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"monkeylang/object"
+)
+
+var objectType = reflect.TypeOf((*object.Object)(nil)).Elem()
+
+func ToObject(value interface{}) (object.Object, error) {
+	return toObject(reflect.ValueOf(value), "", visited{})
+}
+
+func FromObject(obj object.Object) (interface{}, error) {
+	return fromObject(obj, "", visited{})
+}
+
+func Decode(obj object.Object, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return &ConversionError{Message: fmt.Sprintf("decode target must be a non-nil pointer, got %T", target)}
+	}
+
+	return decode(obj, v.Elem(), "", visited{})
+}
+
+func toObject(v reflect.Value, path string, seen visited) (object.Object, error) {
+	if !v.IsValid() {
+		return object.NULL, nil
+	}
+
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return object.NULL, nil
+	}
+
+	if v.Type().Implements(objectType) {
+		return v.Interface().(object.Object), nil
+	}
+
+	if key, ok := referenceKey(v); ok {
+		if !seen.enter(key) {
+			return nil, conversionErrorf(path, "cannot convert cyclic %s", v.Type())
+		}
+		defer seen.leave(key)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return nativeBoolToBoolean(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &object.Integer{Value: v.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return nil, conversionErrorf(path, "%d overflows INTEGER", v.Uint())
+		}
+		return &object.Integer{Value: int64(v.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return &object.Float{Value: v.Float()}, nil
+	case reflect.String:
+		return &object.String{Value: v.String()}, nil
+	case reflect.Ptr, reflect.Interface:
+		return toObject(v.Elem(), path, seen)
+	case reflect.Slice, reflect.Array:
+		return toArray(v, path, seen)
+	case reflect.Map:
+		return toHash(v, path, seen)
+	case reflect.Struct:
+		return structToHash(v, path, seen)
+	default:
+		return nil, conversionErrorf(path, "cannot convert %s to a Monkey value", v.Type())
+	}
+}
+
+func toArray(v reflect.Value, path string, seen visited) (object.Object, error) {
+	elements := make([]object.Object, v.Len())
+
+	for i := range elements {
+		element, err := toObject(v.Index(i), fmt.Sprintf("%s[%d]", path, i), seen)
+		if err != nil {
+			return nil, err
+		}
+
+		elements[i] = element
+	}
+
+	return &object.Array{Elements: elements}, nil
+}
+
+func toHash(v reflect.Value, path string, seen visited) (object.Object, error) {
+	pairs := make(map[object.HashKey]object.HashPair, v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		elementPath := fmt.Sprintf("%s[%v]", path, iter.Key())
+
+		key, err := toObject(iter.Key(), elementPath, seen)
+		if err != nil {
+			return nil, err
+		}
+
+		hashable, ok := key.(object.Hashable)
+		if !ok {
+			return nil, conversionErrorf(elementPath, "unusable as hash key: %s", key.Type())
+		}
+
+		value, err := toObject(iter.Value(), elementPath, seen)
+		if err != nil {
+			return nil, err
+		}
+
+		pairs[hashable.HashKey()] = object.HashPair{Key: key, Value: value}
+	}
+
+	return &object.Hash{Pairs: pairs}, nil
+}
+
+func structToHash(v reflect.Value, path string, seen visited) (object.Object, error) {
+	pairs := make(map[object.HashKey]object.HashPair)
+
+	for _, field := range structFields(v.Type()) {
+		fieldValue := v.Field(field.index)
+		if field.omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+
+		value, err := toObject(fieldValue, fieldPath(path, field.name), seen)
+		if err != nil {
+			return nil, err
+		}
+
+		key := &object.String{Value: field.name}
+		pairs[key.HashKey()] = object.HashPair{Key: key, Value: value}
+	}
+
+	return &object.Hash{Pairs: pairs}, nil
+}
+
+func fromObject(obj object.Object, path string, seen visited) (interface{}, error) {
+	switch obj := obj.(type) {
+	case *object.Null:
+		return nil, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	case *object.Integer:
+		return obj.Value, nil
+	case *object.Float:
+		return obj.Value, nil
+	case *object.String:
+		return obj.Value, nil
+	case *object.Array:
+		if !seen.enter(obj) {
+			return nil, conversionErrorf(path, "cannot convert cyclic %s", obj.Type())
+		}
+		defer seen.leave(obj)
+
+		elements := make([]interface{}, len(obj.Elements))
+
+		for i, element := range obj.Elements {
+			value, err := fromObject(element, fmt.Sprintf("%s[%d]", path, i), seen)
+			if err != nil {
+				return nil, err
+			}
+
+			elements[i] = value
+		}
+
+		return elements, nil
+	case *object.Hash:
+		if !seen.enter(obj) {
+			return nil, conversionErrorf(path, "cannot convert cyclic %s", obj.Type())
+		}
+		defer seen.leave(obj)
+
+		values := make(map[string]interface{}, len(obj.Pairs))
+
+		for _, pair := range obj.SortedPairs() {
+			key, ok := pair.Key.(*object.String)
+			if !ok {
+				return nil, conversionErrorf(path, "cannot convert %s hash key to a Go string", pair.Key.Type())
+			}
+
+			value, err := fromObject(pair.Value, fieldPath(path, key.Value), seen)
+			if err != nil {
+				return nil, err
+			}
+
+			values[key.Value] = value
+		}
+
+		return values, nil
+	default:
+		return nil, conversionErrorf(path, "cannot convert %s to a Go value", obj.Type())
+	}
+}
+
+func decode(obj object.Object, v reflect.Value, path string, seen visited) error {
+	if v.Type() == objectType {
+		v.Set(reflect.ValueOf(obj))
+		return nil
+	}
+
+	if obj == object.NULL {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return conversionErrorf(path, "cannot decode into %s", v.Type())
+		}
+
+		value, err := fromObject(obj, path, seen)
+		if err != nil {
+			return err
+		}
+
+		v.Set(reflect.ValueOf(&value).Elem())
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return decode(obj, v.Elem(), path, seen)
+	case reflect.Bool:
+		boolean, ok := obj.(*object.Boolean)
+		if !ok {
+			return mismatch(obj, v, path)
+		}
+
+		v.SetBool(boolean.Value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return mismatch(obj, v, path)
+		}
+
+		if v.OverflowInt(integer.Value) {
+			return conversionErrorf(path, "%d overflows %s", integer.Value, v.Type())
+		}
+
+		v.SetInt(integer.Value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return mismatch(obj, v, path)
+		}
+
+		if integer.Value < 0 || v.OverflowUint(uint64(integer.Value)) {
+			return conversionErrorf(path, "%d overflows %s", integer.Value, v.Type())
+		}
+
+		v.SetUint(uint64(integer.Value))
+	case reflect.Float32, reflect.Float64:
+		switch number := obj.(type) {
+		case *object.Integer:
+			v.SetFloat(float64(number.Value))
+		case *object.Float:
+			if v.OverflowFloat(number.Value) {
+				return conversionErrorf(path, "%s overflows %s", number.Inspect(), v.Type())
+			}
+
+			v.SetFloat(number.Value)
+		default:
+			return mismatch(obj, v, path)
+		}
+	case reflect.String:
+		str, ok := obj.(*object.String)
+		if !ok {
+			return mismatch(obj, v, path)
+		}
+
+		v.SetString(str.Value)
+	case reflect.Slice:
+		array, ok := obj.(*object.Array)
+		if !ok {
+			return mismatch(obj, v, path)
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(array.Elements), len(array.Elements))
+		if err := decodeElements(array, slice, path, seen); err != nil {
+			return err
+		}
+
+		v.Set(slice)
+	case reflect.Array:
+		array, ok := obj.(*object.Array)
+		if !ok {
+			return mismatch(obj, v, path)
+		}
+
+		if len(array.Elements) != v.Len() {
+			return conversionErrorf(path, "cannot decode ARRAY of length %d into %s", len(array.Elements), v.Type())
+		}
+
+		return decodeElements(array, v, path, seen)
+	case reflect.Map:
+		hash, ok := obj.(*object.Hash)
+		if !ok {
+			return mismatch(obj, v, path)
+		}
+
+		return decodeMap(hash, v, path, seen)
+	case reflect.Struct:
+		hash, ok := obj.(*object.Hash)
+		if !ok {
+			return mismatch(obj, v, path)
+		}
+
+		return decodeStruct(hash, v, path, seen)
+	default:
+		return conversionErrorf(path, "cannot decode into %s", v.Type())
+	}
+
+	return nil
+}
+
+func decodeElements(array *object.Array, v reflect.Value, path string, seen visited) error {
+	if !seen.enter(array) {
+		return conversionErrorf(path, "cannot decode cyclic %s", array.Type())
+	}
+	defer seen.leave(array)
+
+	for i, element := range array.Elements {
+		if err := decode(element, v.Index(i), fmt.Sprintf("%s[%d]", path, i), seen); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeMap(hash *object.Hash, v reflect.Value, path string, seen visited) error {
+	if !seen.enter(hash) {
+		return conversionErrorf(path, "cannot decode cyclic %s", hash.Type())
+	}
+	defer seen.leave(hash)
+
+	m := reflect.MakeMapWithSize(v.Type(), len(hash.Pairs))
+
+	for _, pair := range hash.SortedPairs() {
+		elementPath := fmt.Sprintf("%s[%s]", path, pair.Key.Inspect())
+
+		key := reflect.New(v.Type().Key()).Elem()
+		if err := decode(pair.Key, key, elementPath, seen); err != nil {
+			return err
+		}
+
+		value := reflect.New(v.Type().Elem()).Elem()
+		if err := decode(pair.Value, value, elementPath, seen); err != nil {
+			return err
+		}
+
+		m.SetMapIndex(key, value)
+	}
+
+	v.Set(m)
+
+	return nil
+}
+
+func decodeStruct(hash *object.Hash, v reflect.Value, path string, seen visited) error {
+	if !seen.enter(hash) {
+		return conversionErrorf(path, "cannot decode cyclic %s", hash.Type())
+	}
+	defer seen.leave(hash)
+
+	for _, field := range structFields(v.Type()) {
+		key := &object.String{Value: field.name}
+
+		pair, ok := hash.Pairs[key.HashKey()]
+		if !ok {
+			continue
+		}
+
+		if err := decode(pair.Value, v.Field(field.index), fieldPath(path, field.name), seen); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type visited map[interface{}]bool
+
+type reference struct {
+	pointer uintptr
+	typ     reflect.Type
+	length  int
+}
+
+func referenceKey(v reflect.Value) (reference, bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		return reference{pointer: v.Pointer(), typ: v.Type()}, !v.IsNil()
+	case reflect.Slice:
+		return reference{pointer: v.Pointer(), typ: v.Type(), length: v.Len()}, v.Len() > 0
+	default:
+		return reference{}, false
+	}
+}
+
+func (s visited) enter(key interface{}) bool {
+	if s[key] {
+		return false
+	}
+
+	s[key] = true
+	return true
+}
+
+func (s visited) leave(key interface{}) {
+	delete(s, key)
+}
+
+type structField struct {
+	index     int
+	name      string
+	omitEmpty bool
+}
+
+func structFields(t reflect.Type) []structField {
+	fields := []structField{}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		field := structField{index: i, name: f.Name}
+
+		if tag, ok := f.Tag.Lookup("monkey"); ok {
+			if tag == "-" {
+				continue
+			}
+
+			options := strings.Split(tag, ",")
+			if options[0] != "" {
+				field.name = options[0]
+			}
+
+			for _, option := range options[1:] {
+				if option == "omitempty" {
+					field.omitEmpty = true
+				}
+			}
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func mismatch(obj object.Object, v reflect.Value, path string) error {
+	return conversionErrorf(path, "cannot decode %s into %s", obj.Type(), v.Type())
+}
+
+func nativeBoolToBoolean(b bool) *object.Boolean {
+	if b {
+		return object.TRUE
+	}
+
+	return object.FALSE
+}
@@ -0,0 +1,304 @@
+package monkey
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"monkeylang/object"
+)
+
+type server struct {
+	Host    string   `monkey:"host"`
+	Port    int      `monkey:"port"`
+	Tags    []string `monkey:"tags,omitempty"`
+	Secret  string   `monkey:"-"`
+	Enabled bool
+	weight  int
+}
+
+type config struct {
+	Name    string            `monkey:"name"`
+	Servers []server          `monkey:"servers"`
+	Limits  map[string]uint16 `monkey:"limits"`
+	Ratio   float64           `monkey:"ratio"`
+	Parent  *config           `monkey:"parent"`
+	Extra   interface{}       `monkey:"extra"`
+}
+
+func TestToObject(t *testing.T) {
+	shared := []int{1}
+
+	tests := []struct {
+		input    interface{}
+		expected string
+	}{
+		{nil, "null"},
+		{true, "true"},
+		{int8(-3), "-3"},
+		{uint32(7), "7"},
+		{2.5, "2.5"},
+		{float32(1), "1.0"},
+		{"monkey", "monkey"},
+		{[]int{1, 2}, "[1, 2]"},
+		{[2]bool{true, false}, "[true, false]"},
+		{[]interface{}{1, "a", nil}, "[1, a, null]"},
+		{(*int)(nil), "null"},
+		{&server{Host: "a"}, `{Enabled: false, host: a, port: 0}`},
+		{map[int]string{2: "b", 1: "a"}, "{1: a, 2: b}"},
+		{&object.Integer{Value: 5}, "5"},
+		{[][]int{shared, shared}, "[[1], [1]]"},
+	}
+
+	for _, tt := range tests {
+		obj, err := ToObject(tt.input)
+		if err != nil {
+			t.Errorf("ToObject(%#v) returned error: %s", tt.input, err)
+			continue
+		}
+
+		if got := inspectSorted(obj); got != tt.expected {
+			t.Errorf("ToObject(%#v) wrong. want=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestToObjectErrors(t *testing.T) {
+	list := []interface{}{nil}
+	list[0] = list
+	hash := map[string]interface{}{}
+	hash["self"] = hash
+	parent := &config{}
+	parent.Parent = parent
+
+	tests := []struct {
+		input    interface{}
+		expected string
+	}{
+		{make(chan int), "cannot convert chan int to a Monkey value"},
+		{uint64(math.MaxUint64), "18446744073709551615 overflows INTEGER"},
+		{[]interface{}{1, func() {}}, "[1]: cannot convert func() to a Monkey value"},
+		{map[[2]int]int{{1, 2}: 3}, "[[1 2]]: unusable as hash key: ARRAY"},
+		{config{Servers: []server{{}, {Tags: []string{"x"}}}, Extra: complex(1, 2)}, "extra: cannot convert complex128 to a Monkey value"},
+		{list, "[0]: cannot convert cyclic []interface {}"},
+		{hash, "[self]: cannot convert cyclic map[string]interface {}"},
+		{parent, "parent: cannot convert cyclic *monkey.config"},
+	}
+
+	for _, tt := range tests {
+		_, err := ToObject(tt.input)
+
+		var conversionErr *ConversionError
+		if !errors.As(err, &conversionErr) {
+			t.Errorf("ToObject(%T) expected *ConversionError, got=%T (%v)", tt.input, err, err)
+			continue
+		}
+
+		if err.Error() != tt.expected {
+			t.Errorf("ToObject(%T) wrong error. want=%q, got=%q", tt.input, tt.expected, err.Error())
+		}
+	}
+}
+
+func TestFromObject(t *testing.T) {
+	obj, err := New().Run(`{"a": [1, 2.5, "x", true, null], "b": {"c": 1}}`)
+	if err != nil {
+		t.Fatalf("Run returned error: %s", err)
+	}
+
+	value, err := FromObject(obj)
+	if err != nil {
+		t.Fatalf("FromObject returned error: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"a": []interface{}{int64(1), 2.5, "x", true, nil},
+		"b": map[string]interface{}{"c": int64(1)},
+	}
+
+	if !reflect.DeepEqual(value, expected) {
+		t.Errorf("FromObject wrong. want=%#v, got=%#v", expected, value)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`fn(x) { x }`, "cannot convert FUNCTION to a Go value"},
+		{`{1: "a"}`, "cannot convert INTEGER hash key to a Go string"},
+		{`{"a": [1, fn() { 1 }]}`, "a[1]: cannot convert FUNCTION to a Go value"},
+	}
+
+	for _, tt := range tests {
+		obj, err := New().Run(tt.input)
+		if err != nil {
+			t.Fatalf("Run(%q) returned error: %s", tt.input, err)
+		}
+
+		if _, err := FromObject(obj); err == nil || err.Error() != tt.expected {
+			t.Errorf("FromObject(%s) wrong error. want=%q, got=%v", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestDecodeStruct(t *testing.T) {
+	for _, backend := range backends {
+		interp := NewWithBackend(backend)
+
+		defaults := config{
+			Name:    "default",
+			Servers: []server{{Host: "localhost", Port: 80, Secret: "hidden", Enabled: true}},
+			Limits:  map[string]uint16{"conns": 10},
+			Ratio:   0.5,
+		}
+
+		if err := interp.Set("defaults", defaults); err != nil {
+			t.Fatalf("[%s] Set returned error: %s", backend, err)
+		}
+
+		result, err := interp.Run(`
+			let cfg = defaults;
+			cfg["name"] = "prod";
+			cfg["servers"][0]["port"] = 8080;
+			cfg["servers"] = push(cfg["servers"], {"host": "db", "port": 5432, "tags": ["sql"], "Secret": "ignored"});
+			cfg["limits"]["conns"] *= 3;
+			cfg["ratio"] = 2;
+			cfg["parent"] = {"name": "base"};
+			cfg["extra"] = [1, "two"];
+			cfg
+		`)
+		if err != nil {
+			t.Fatalf("[%s] Run returned error: %s", backend, err)
+		}
+
+		decoded := config{Name: "unset"}
+		if err := Decode(result, &decoded); err != nil {
+			t.Fatalf("[%s] Decode returned error: %s", backend, err)
+		}
+
+		expected := config{
+			Name: "prod",
+			Servers: []server{
+				{Host: "localhost", Port: 8080, Enabled: true},
+				{Host: "db", Port: 5432, Tags: []string{"sql"}},
+			},
+			Limits: map[string]uint16{"conns": 30},
+			Ratio:  2,
+			Parent: &config{Name: "base"},
+			Extra:  []interface{}{int64(1), "two"},
+		}
+
+		if !reflect.DeepEqual(decoded, expected) {
+			t.Errorf("[%s] Decode wrong.\nwant=%+v\ngot=%+v", backend, expected, decoded)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var integers map[int]string
+	if err := Decode(mustRun(t, `{2: "b", 1: "a"}`), &integers); err != nil {
+		t.Fatalf("Decode returned error: %s", err)
+	}
+	if !reflect.DeepEqual(integers, map[int]string{1: "a", 2: "b"}) {
+		t.Errorf("wrong map. got=%v", integers)
+	}
+
+	var pair [2]float32
+	if err := Decode(mustRun(t, `[1, 2.5]`), &pair); err != nil {
+		t.Fatalf("Decode returned error: %s", err)
+	}
+	if pair != [2]float32{1, 2.5} {
+		t.Errorf("wrong array. got=%v", pair)
+	}
+
+	names := []string{"stale"}
+	if err := Decode(mustRun(t, `null`), &names); err != nil {
+		t.Fatalf("Decode returned error: %s", err)
+	}
+	if names != nil {
+		t.Errorf("expected nil slice. got=%v", names)
+	}
+
+	var raw object.Object
+	if err := Decode(mustRun(t, `fn(x) { x }`), &raw); err != nil {
+		t.Fatalf("Decode returned error: %s", err)
+	}
+	if _, ok := raw.(*object.Function); !ok {
+		t.Errorf("expected *object.Function. got=%T", raw)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var s server
+	var n int8
+	var u uint
+	var f float32
+	var list []interface{}
+	var nested [][]int
+	var pair [2]int
+	var str string
+	var stringer interface{ String() string }
+
+	tests := []struct {
+		input    string
+		target   interface{}
+		expected string
+	}{
+		{`1`, s, "decode target must be a non-nil pointer, got monkey.server"},
+		{`"x"`, &n, "cannot decode STRING into int8"},
+		{`300`, &n, "300 overflows int8"},
+		{`-1`, &u, "-1 overflows uint"},
+		{`1e39`, &f, "1e+39 overflows float32"},
+		{`-1e39`, &f, "-1e+39 overflows float32"},
+		{`let a = [1]; a[0] = a; a`, &list, "[0]: cannot convert cyclic ARRAY"},
+		{`let a = [1]; a[0] = a; a`, &nested, "[0]: cannot decode cyclic ARRAY"},
+		{`null`, &str, "cannot decode NULL into string"},
+		{`[1, 2, 3]`, &pair, "cannot decode ARRAY of length 3 into [2]int"},
+		{`{"host": 1}`, &s, "host: cannot decode INTEGER into string"},
+		{`{"tags": ["a", 2]}`, &s, "tags[1]: cannot decode INTEGER into string"},
+		{`1`, &stringer, "cannot decode into interface { String() string }"},
+	}
+
+	for _, tt := range tests {
+		err := Decode(mustRun(t, tt.input), tt.target)
+
+		var conversionErr *ConversionError
+		if !errors.As(err, &conversionErr) {
+			t.Errorf("Decode(%s) expected *ConversionError, got=%T (%v)", tt.input, err, err)
+			continue
+		}
+
+		if err.Error() != tt.expected {
+			t.Errorf("Decode(%s) wrong error. want=%q, got=%q", tt.input, tt.expected, err.Error())
+		}
+	}
+}
+
+func mustRun(t *testing.T, input string) object.Object {
+	t.Helper()
+
+	obj, err := New().Run(input)
+	if err != nil {
+		t.Fatalf("Run(%q) returned error: %s", input, err)
+	}
+
+	return obj
+}
+
+func inspectSorted(obj object.Object) string {
+	hash, ok := obj.(*object.Hash)
+	if !ok {
+		return obj.Inspect()
+	}
+
+	out := "{"
+	for i, pair := range hash.SortedPairs() {
+		if i > 0 {
+			out += ", "
+		}
+		out += pair.Key.Inspect() + ": " + inspectSorted(pair.Value)
+	}
+
+	return out + "}"
+}
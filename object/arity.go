@@ -0,0 +1,16 @@
+package object
+
+func CheckArity(required, optional int, variadic bool, got int) *Error {
+	switch {
+	case variadic && got < required:
+		return newError("wrong number of arguments: want=%d or more, got=%d", required, got)
+	case variadic:
+		return nil
+	case got >= required && got <= required+optional:
+		return nil
+	case optional > 0:
+		return newError("wrong number of arguments: want=%d..%d, got=%d", required, required+optional, got)
+	default:
+		return newError("wrong number of arguments: want=%d, got=%d", required, got)
+	}
+}
@@ -0,0 +1,700 @@
+package object
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"monkeylang/ast"
+	"monkeylang/token"
+)
+
+var prefixOperators = map[string]bool{"!": true, "-": true}
+
+var infixOperators = map[string]bool{
+	"+": true, "-": true, "*": true, "/": true, "%": true,
+	"<": true, ">": true, "<=": true, ">=": true, "==": true, "!=": true,
+	"&&": true, "||": true,
+}
+
+var assignOperators = map[string]bool{
+	"=": true, "+=": true, "-=": true, "*=": true, "/=": true, "%=": true,
+}
+
+func nodeKind(node ast.Node) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast.")
+}
+
+func nodeFields(node ast.Node) *Hash {
+	fields := map[string]Object{}
+
+	switch node := node.(type) {
+	case *ast.Program:
+		fields["statements"] = quoteStatements(node.Statements)
+	case *ast.ExpressionStatement:
+		fields["expression"] = quoteNode(node.Expression)
+	case *ast.BlockStatement:
+		fields["statements"] = quoteStatements(node.Statements)
+	case *ast.LetStatement:
+		fields["name"] = quoteNode(node.Name)
+		fields["value"] = quoteNode(node.Value)
+	case *ast.ReturnStatement:
+		fields["value"] = quoteNode(node.ReturnValue)
+	case *ast.Identifier:
+		fields["name"] = &String{Value: node.Value}
+	case *ast.IntegerLiteral:
+		fields["value"] = &Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		fields["value"] = &Float{Value: node.Value}
+	case *ast.StringLiteral:
+		fields["value"] = &String{Value: node.Value}
+	case *ast.Boolean:
+		fields["value"] = FALSE
+		if node.Value {
+			fields["value"] = TRUE
+		}
+	case *ast.PrefixExpression:
+		fields["operator"] = &String{Value: node.Operator}
+		fields["right"] = quoteNode(node.Right)
+	case *ast.InfixExpression:
+		fields["operator"] = &String{Value: node.Operator}
+		fields["left"] = quoteNode(node.Left)
+		fields["right"] = quoteNode(node.Right)
+	case *ast.AssignExpression:
+		fields["operator"] = &String{Value: node.Operator}
+		fields["target"] = quoteNode(node.Target)
+		fields["value"] = quoteNode(node.Value)
+	case *ast.CallExpression:
+		fields["function"] = quoteNode(node.Function)
+		fields["arguments"] = quoteExpressions(node.Arguments)
+	case *ast.IndexExpression:
+		fields["left"] = quoteNode(node.Left)
+		fields["index"] = quoteNode(node.Index)
+	case *ast.SpreadExpression:
+		fields["value"] = quoteNode(node.Value)
+	case *ast.ThrowExpression:
+		fields["value"] = quoteNode(node.Value)
+	case *ast.IfExpression:
+		fields["condition"] = quoteNode(node.Condition)
+		fields["consequence"] = quoteNode(node.Consequence)
+		fields["alternative"] = quoteNode(node.Alternative)
+	case *ast.WhileExpression:
+		fields["condition"] = quoteNode(node.Condition)
+		fields["body"] = quoteNode(node.Body)
+	case *ast.ForExpression:
+		fields["key"] = quoteNode(node.Key)
+		fields["value"] = quoteNode(node.Value)
+		fields["collection"] = quoteNode(node.Collection)
+		fields["body"] = quoteNode(node.Body)
+	case *ast.TryExpression:
+		fields["block"] = quoteNode(node.Block)
+		fields["catch_parameter"] = quoteNode(node.CatchParameter)
+		fields["catch"] = quoteNode(node.Catch)
+		fields["finally"] = quoteNode(node.Finally)
+	case *ast.FunctionLiteral:
+		fields["name"] = &String{Value: node.Name}
+		fields["parameters"] = quoteIdentifiers(node.Parameters)
+		defaults := make([]Object, len(node.Parameters))
+		for i := range node.Parameters {
+			defaults[i] = quoteNode(node.Default(i))
+		}
+		fields["defaults"] = &Array{Elements: defaults}
+		fields["rest"] = quoteNode(node.Rest)
+		fields["body"] = quoteNode(node.Body)
+	case *ast.MacroLiteral:
+		fields["parameters"] = quoteIdentifiers(node.Parameters)
+		fields["rest"] = quoteNode(node.Rest)
+		fields["body"] = quoteNode(node.Body)
+	case *ast.ArrayLiteral:
+		fields["elements"] = quoteExpressions(node.Elements)
+	case *ast.HashLiteral:
+		keys := make([]ast.Expression, 0, len(node.Pairs))
+		for key := range node.Pairs {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+		pairs := make([]Object, len(keys))
+		for i, key := range keys {
+			pairs[i] = &Array{Elements: []Object{quoteNode(key), quoteNode(node.Pairs[key])}}
+		}
+		fields["pairs"] = &Array{Elements: pairs}
+	}
+
+	hash := &Hash{Pairs: make(map[HashKey]HashPair, len(fields))}
+	for name, value := range fields {
+		key := &String{Value: name}
+		hash.Pairs[key.HashKey()] = HashPair{Key: key, Value: value}
+	}
+
+	return hash
+}
+
+func quoteNode(node ast.Node) Object {
+	switch node := node.(type) {
+	case nil:
+		return NULL
+	case *ast.Identifier:
+		if node == nil {
+			return NULL
+		}
+	case *ast.BlockStatement:
+		if node == nil {
+			return NULL
+		}
+	}
+
+	return &Quote{Node: node}
+}
+
+func quoteStatements(stmts []ast.Statement) *Array {
+	elements := make([]Object, len(stmts))
+	for i, stmt := range stmts {
+		elements[i] = quoteNode(stmt)
+	}
+
+	return &Array{Elements: elements}
+}
+
+func quoteExpressions(exps []ast.Expression) *Array {
+	elements := make([]Object, len(exps))
+	for i, exp := range exps {
+		elements[i] = quoteNode(exp)
+	}
+
+	return &Array{Elements: elements}
+}
+
+func quoteIdentifiers(identifiers []*ast.Identifier) *Array {
+	elements := make([]Object, len(identifiers))
+	for i, identifier := range identifiers {
+		elements[i] = quoteNode(identifier)
+	}
+
+	return &Array{Elements: elements}
+}
+
+type nodeBuilder struct {
+	kind   string
+	fields *Hash
+	err    *Error
+}
+
+func buildNode(kind string, fields *Hash) (ast.Node, *Error) {
+	b := &nodeBuilder{kind: kind, fields: fields}
+
+	var node ast.Node
+
+	switch kind {
+	case "Program":
+		node = &ast.Program{Statements: b.statements("statements")}
+	case "ExpressionStatement":
+		exp := b.expression("expression")
+		node = &ast.ExpressionStatement{Token: expressionToken(exp), Expression: exp}
+	case "BlockStatement":
+		node = &ast.BlockStatement{
+			Token:      token.Token{Type: token.LBRACE, Literal: "{"},
+			Statements: b.statements("statements"),
+			EndToken:   token.Token{Type: token.RBRACE, Literal: "}"},
+		}
+	case "LetStatement":
+		node = &ast.LetStatement{
+			Token: token.Token{Type: token.LET, Literal: "let"},
+			Name:  b.identifier("name"),
+			Value: b.expression("value"),
+		}
+	case "ReturnStatement":
+		node = &ast.ReturnStatement{
+			Token:       token.Token{Type: token.RETURN, Literal: "return"},
+			ReturnValue: b.expression("value"),
+		}
+	case "BreakStatement":
+		node = &ast.BreakStatement{Token: token.Token{Type: token.BREAK, Literal: "break"}}
+	case "ContinueStatement":
+		node = &ast.ContinueStatement{Token: token.Token{Type: token.CONTINUE, Literal: "continue"}}
+	case "Identifier":
+		name := b.str("name")
+		if b.err == nil && !isIdentifierName(name) {
+			b.err = &Error{Kind: TYPE_ERROR, Message: fmt.Sprintf("invalid identifier name for Identifier: %q", name)}
+		}
+		node = &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	case "IntegerLiteral":
+		value := b.integer("value")
+		node = &ast.IntegerLiteral{
+			Token: token.Token{Type: token.INT, Literal: strconv.FormatInt(value, 10)},
+			Value: value,
+		}
+	case "FloatLiteral":
+		value := b.float("value")
+		node = &ast.FloatLiteral{
+			Token: token.Token{Type: token.FLOAT, Literal: (&Float{Value: value}).Inspect()},
+			Value: value,
+		}
+	case "StringLiteral":
+		value := b.str("value")
+		node = &ast.StringLiteral{Token: token.Token{Type: token.STRING, Literal: value}, Value: value}
+	case "Boolean":
+		if b.boolean("value") {
+			node = &ast.Boolean{Token: token.Token{Type: token.TRUE, Literal: "true"}, Value: true}
+		} else {
+			node = &ast.Boolean{Token: token.Token{Type: token.FALSE, Literal: "false"}, Value: false}
+		}
+	case "Null":
+		node = &ast.Null{Token: token.Token{Type: token.NULL, Literal: "null"}}
+	case "PrefixExpression":
+		operator := b.operator("operator", prefixOperators)
+		node = &ast.PrefixExpression{
+			Token:    token.Token{Type: token.TokenType(operator), Literal: operator},
+			Operator: operator,
+			Right:    b.expression("right"),
+		}
+	case "InfixExpression":
+		operator := b.operator("operator", infixOperators)
+		node = &ast.InfixExpression{
+			Token:    token.Token{Type: token.TokenType(operator), Literal: operator},
+			Left:     b.expression("left"),
+			Operator: operator,
+			Right:    b.expression("right"),
+		}
+	case "AssignExpression":
+		operator := b.operator("operator", assignOperators)
+		node = &ast.AssignExpression{
+			Token:    token.Token{Type: token.TokenType(operator), Literal: operator},
+			Target:   b.expression("target"),
+			Operator: operator,
+			Value:    b.expression("value"),
+		}
+	case "CallExpression":
+		node = &ast.CallExpression{
+			Token:     token.Token{Type: token.LPAREN, Literal: "("},
+			Function:  b.expression("function"),
+			Arguments: b.expressions("arguments"),
+			EndToken:  token.Token{Type: token.RPAREN, Literal: ")"},
+		}
+	case "IndexExpression":
+		node = &ast.IndexExpression{
+			Token:    token.Token{Type: token.LBRACKET, Literal: "["},
+			Left:     b.expression("left"),
+			Index:    b.expression("index"),
+			EndToken: token.Token{Type: token.RBRACKET, Literal: "]"},
+		}
+	case "SpreadExpression":
+		node = &ast.SpreadExpression{
+			Token: token.Token{Type: token.ELLIPSIS, Literal: "..."},
+			Value: b.expression("value"),
+		}
+	case "ThrowExpression":
+		node = &ast.ThrowExpression{
+			Token: token.Token{Type: token.THROW, Literal: "throw"},
+			Value: b.expression("value"),
+		}
+	case "IfExpression":
+		node = &ast.IfExpression{
+			Token:       token.Token{Type: token.IF, Literal: "if"},
+			Condition:   b.expression("condition"),
+			Consequence: b.block("consequence"),
+			Alternative: b.optionalBlock("alternative"),
+		}
+	case "WhileExpression":
+		node = &ast.WhileExpression{
+			Token:     token.Token{Type: token.WHILE, Literal: "while"},
+			Condition: b.expression("condition"),
+			Body:      b.block("body"),
+		}
+	case "ForExpression":
+		node = &ast.ForExpression{
+			Token:      token.Token{Type: token.FOR, Literal: "for"},
+			Key:        b.optionalIdentifier("key"),
+			Value:      b.identifier("value"),
+			Collection: b.expression("collection"),
+			Body:       b.block("body"),
+		}
+	case "TryExpression":
+		try := &ast.TryExpression{
+			Token:          token.Token{Type: token.TRY, Literal: "try"},
+			Block:          b.block("block"),
+			CatchParameter: b.optionalIdentifier("catch_parameter"),
+			Catch:          b.optionalBlock("catch"),
+			Finally:        b.optionalBlock("finally"),
+		}
+		if b.err == nil && try.Catch == nil && try.Finally == nil {
+			b.fail("%s needs a catch or a finally block", kind)
+		}
+		node = try
+	case "FunctionLiteral":
+		fn := &ast.FunctionLiteral{
+			Token:      token.Token{Type: token.FUNCTION, Literal: "fn"},
+			Name:       b.optionalStr("name"),
+			Parameters: b.identifiers("parameters"),
+			Defaults:   b.optionalExpressions("defaults"),
+			Rest:       b.optionalIdentifier("rest"),
+			Body:       b.block("body"),
+		}
+		if len(fn.Defaults) > len(fn.Parameters) {
+			b.fail("%s has more defaults than parameters", kind)
+		}
+		node = fn
+	case "MacroLiteral":
+		node = &ast.MacroLiteral{
+			Token:      token.Token{Type: token.MACRO, Literal: "macro"},
+			Parameters: b.identifiers("parameters"),
+			Rest:       b.optionalIdentifier("rest"),
+			Body:       b.block("body"),
+		}
+	case "ArrayLiteral":
+		node = &ast.ArrayLiteral{
+			Token:    token.Token{Type: token.LBRACKET, Literal: "["},
+			Elements: b.expressions("elements"),
+			EndToken: token.Token{Type: token.RBRACKET, Literal: "]"},
+		}
+	case "HashLiteral":
+		node = &ast.HashLiteral{
+			Token:    token.Token{Type: token.LBRACE, Literal: "{"},
+			Pairs:    b.pairs("pairs"),
+			EndToken: token.Token{Type: token.RBRACE, Literal: "}"},
+		}
+	default:
+		return nil, newError("unknown node kind: %s", kind)
+	}
+
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	return node, nil
+}
+
+func expressionToken(exp ast.Expression) token.Token {
+	if exp == nil {
+		return token.Token{}
+	}
+
+	return token.Token{Literal: exp.TokenLiteral(), Start: exp.Pos()}
+}
+
+func (b *nodeBuilder) fail(format string, a ...interface{}) {
+	if b.err == nil {
+		b.err = newError(format, a...)
+	}
+}
+
+func (b *nodeBuilder) get(name string) (Object, bool) {
+	pair, ok := b.fields.Pairs[(&String{Value: name}).HashKey()]
+	if !ok || pair.Value == NULL {
+		return nil, false
+	}
+
+	return pair.Value, true
+}
+
+func (b *nodeBuilder) required(name string) Object {
+	value, ok := b.get(name)
+	if !ok {
+		b.fail("missing field %q for %s", name, b.kind)
+	}
+
+	return value
+}
+
+func (b *nodeBuilder) quoted(name string, value Object) ast.Node {
+	quote, ok := value.(*Quote)
+	if !ok {
+		b.fail("field %q of %s must be QUOTE, got %s", name, b.kind, value.Type())
+		return nil
+	}
+
+	return quote.Node
+}
+
+func (b *nodeBuilder) toExpression(name string, value Object) ast.Expression {
+	node := b.quoted(name, value)
+
+	switch node := node.(type) {
+	case nil:
+		return nil
+	case ast.Expression:
+		return node
+	case *ast.ExpressionStatement:
+		return node.Expression
+	default:
+		b.fail("field %q of %s must be an expression, got %s", name, b.kind, nodeKind(node))
+		return nil
+	}
+}
+
+func (b *nodeBuilder) toStatement(name string, value Object) ast.Statement {
+	node := b.quoted(name, value)
+
+	switch node := node.(type) {
+	case nil:
+		return nil
+	case ast.Statement:
+		return node
+	case ast.Expression:
+		return &ast.ExpressionStatement{Token: expressionToken(node), Expression: node}
+	default:
+		b.fail("field %q of %s must be a statement, got %s", name, b.kind, nodeKind(node))
+		return nil
+	}
+}
+
+func (b *nodeBuilder) toIdentifier(name string, value Object) *ast.Identifier {
+	exp := b.toExpression(name, value)
+	if exp == nil {
+		return nil
+	}
+
+	identifier, ok := exp.(*ast.Identifier)
+	if !ok {
+		b.fail("field %q of %s must be an Identifier, got %s", name, b.kind, nodeKind(exp))
+		return nil
+	}
+
+	return identifier
+}
+
+func (b *nodeBuilder) toBlock(name string, value Object) *ast.BlockStatement {
+	node := b.quoted(name, value)
+	if node == nil {
+		return nil
+	}
+
+	if block, ok := node.(*ast.BlockStatement); ok {
+		return block
+	}
+
+	stmt := b.toStatement(name, value)
+	if stmt == nil {
+		return nil
+	}
+
+	return &ast.BlockStatement{
+		Token:      token.Token{Type: token.LBRACE, Literal: "{"},
+		Statements: []ast.Statement{stmt},
+		EndToken:   token.Token{Type: token.RBRACE, Literal: "}"},
+	}
+}
+
+func (b *nodeBuilder) array(name string) []Object {
+	value, ok := b.get(name)
+	if !ok {
+		return nil
+	}
+
+	array, ok := value.(*Array)
+	if !ok {
+		b.fail("field %q of %s must be ARRAY, got %s", name, b.kind, value.Type())
+		return nil
+	}
+
+	return array.Elements
+}
+
+func (b *nodeBuilder) expression(name string) ast.Expression {
+	value := b.required(name)
+	if value == nil {
+		return nil
+	}
+
+	return b.toExpression(name, value)
+}
+
+func (b *nodeBuilder) identifier(name string) *ast.Identifier {
+	value := b.required(name)
+	if value == nil {
+		return nil
+	}
+
+	return b.toIdentifier(name, value)
+}
+
+func (b *nodeBuilder) optionalIdentifier(name string) *ast.Identifier {
+	value, ok := b.get(name)
+	if !ok {
+		return nil
+	}
+
+	return b.toIdentifier(name, value)
+}
+
+func (b *nodeBuilder) block(name string) *ast.BlockStatement {
+	value := b.required(name)
+	if value == nil {
+		return nil
+	}
+
+	return b.toBlock(name, value)
+}
+
+func (b *nodeBuilder) optionalBlock(name string) *ast.BlockStatement {
+	value, ok := b.get(name)
+	if !ok {
+		return nil
+	}
+
+	return b.toBlock(name, value)
+}
+
+func (b *nodeBuilder) statements(name string) []ast.Statement {
+	stmts := []ast.Statement{}
+	for _, el := range b.array(name) {
+		stmts = append(stmts, b.toStatement(name, el))
+	}
+
+	return stmts
+}
+
+func (b *nodeBuilder) expressions(name string) []ast.Expression {
+	exps := []ast.Expression{}
+	for _, el := range b.array(name) {
+		exps = append(exps, b.toExpression(name, el))
+	}
+
+	return exps
+}
+
+func (b *nodeBuilder) optionalExpressions(name string) []ast.Expression {
+	var exps []ast.Expression
+	present := false
+
+	for _, el := range b.array(name) {
+		if el == NULL {
+			exps = append(exps, nil)
+			continue
+		}
+
+		exps = append(exps, b.toExpression(name, el))
+		present = true
+	}
+
+	if !present {
+		return nil
+	}
+
+	return exps
+}
+
+func (b *nodeBuilder) identifiers(name string) []*ast.Identifier {
+	identifiers := []*ast.Identifier{}
+	for _, el := range b.array(name) {
+		identifiers = append(identifiers, b.toIdentifier(name, el))
+	}
+
+	return identifiers
+}
+
+func (b *nodeBuilder) pairs(name string) map[ast.Expression]ast.Expression {
+	pairs := map[ast.Expression]ast.Expression{}
+
+	for _, el := range b.array(name) {
+		pair, ok := el.(*Array)
+		if !ok || len(pair.Elements) != 2 {
+			b.fail("field %q of %s must contain [key, value] pairs", name, b.kind)
+			return pairs
+		}
+
+		key := b.toExpression(name, pair.Elements[0])
+		value := b.toExpression(name, pair.Elements[1])
+		if key != nil && value != nil {
+			pairs[key] = value
+		}
+	}
+
+	return pairs
+}
+
+func (b *nodeBuilder) str(name string) string {
+	value := b.required(name)
+	if value == nil {
+		return ""
+	}
+
+	str, ok := value.(*String)
+	if !ok {
+		b.fail("field %q of %s must be STRING, got %s", name, b.kind, value.Type())
+		return ""
+	}
+
+	return str.Value
+}
+
+func (b *nodeBuilder) optionalStr(name string) string {
+	if _, ok := b.get(name); !ok {
+		return ""
+	}
+
+	return b.str(name)
+}
+
+func isIdentifierName(name string) bool {
+	if name == "" || token.LookupIdentifier(name) != token.IDENT {
+		return false
+	}
+
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		letter := 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_'
+		if !letter && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b *nodeBuilder) operator(name string, allowed map[string]bool) string {
+	operator := b.str(name)
+	if b.err == nil && !allowed[operator] {
+		b.fail("invalid operator for %s: %s", b.kind, operator)
+	}
+
+	return operator
+}
+
+func (b *nodeBuilder) integer(name string) int64 {
+	value := b.required(name)
+	if value == nil {
+		return 0
+	}
+
+	integer, ok := value.(*Integer)
+	if !ok {
+		b.fail("field %q of %s must be INTEGER, got %s", name, b.kind, value.Type())
+		return 0
+	}
+
+	return integer.Value
+}
+
+func (b *nodeBuilder) float(name string) float64 {
+	value := b.required(name)
+	if value == nil {
+		return 0
+	}
+
+	switch value := value.(type) {
+	case *Float:
+		return value.Value
+	case *Integer:
+		return float64(value.Value)
+	default:
+		b.fail("field %q of %s must be FLOAT, got %s", name, b.kind, value.Type())
+		return 0
+	}
+}
+
+func (b *nodeBuilder) boolean(name string) bool {
+	value := b.required(name)
+	if value == nil {
+		return false
+	}
+
+	boolean, ok := value.(*Boolean)
+	if !ok {
+		b.fail("field %q of %s must be BOOLEAN, got %s", name, b.kind, value.Type())
+		return false
+	}
+
+	return boolean.Value
+}
@@ -0,0 +1,143 @@
+package object
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sort"
+)
+
+var defaultStdin = bufio.NewReader(os.Stdin)
+
+type BuiltinRegistry struct {
+	names    []string
+	builtins []*Builtin
+	index    map[string]int
+
+	stdout io.Writer
+	stderr io.Writer
+	stdin  *bufio.Reader
+
+	gensyms int
+}
+
+func NewEmptyBuiltinRegistry() *BuiltinRegistry {
+	return &BuiltinRegistry{
+		index:  make(map[string]int),
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		stdin:  defaultStdin,
+	}
+}
+
+func NewBuiltinRegistry() *BuiltinRegistry {
+	r := NewEmptyBuiltinRegistry()
+
+	for _, def := range Builtins {
+		r.Define(def.Name, def.Builtin)
+	}
+
+	r.defineIOBuiltins()
+	r.defineMacroBuiltins()
+
+	r.Define("builtins", &Builtin{
+		Fn: func(args ...Object) Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. want=0, got=%d", len(args))
+			}
+
+			names := r.Names()
+			elements := make([]Object, len(names))
+			for i, name := range names {
+				elements[i] = &String{Value: name}
+			}
+
+			return &Array{Elements: elements}
+		},
+	})
+
+	return r
+}
+
+func (r *BuiltinRegistry) SetStdout(w io.Writer) {
+	r.stdout = w
+}
+
+func (r *BuiltinRegistry) SetStderr(w io.Writer) {
+	r.stderr = w
+}
+
+func (r *BuiltinRegistry) SetStdin(rd io.Reader) {
+	if buffered, ok := rd.(*bufio.Reader); ok {
+		r.stdin = buffered
+		return
+	}
+
+	r.stdin = bufio.NewReader(rd)
+}
+
+func (r *BuiltinRegistry) Define(name string, builtin *Builtin) int {
+	if i, ok := r.index[name]; ok {
+		r.builtins[i] = builtin
+		return i
+	}
+
+	r.index[name] = len(r.builtins)
+	r.names = append(r.names, name)
+	r.builtins = append(r.builtins, builtin)
+
+	return len(r.builtins) - 1
+}
+
+func (r *BuiltinRegistry) Remove(name string) bool {
+	i, ok := r.index[name]
+	if !ok {
+		return false
+	}
+
+	delete(r.index, name)
+	r.builtins[i] = nil
+
+	return true
+}
+
+func (r *BuiltinRegistry) Get(name string) (*Builtin, bool) {
+	i, ok := r.index[name]
+	if !ok {
+		return nil, false
+	}
+
+	return r.builtins[i], true
+}
+
+func (r *BuiltinRegistry) Index(name string) (int, bool) {
+	i, ok := r.index[name]
+	return i, ok
+}
+
+func (r *BuiltinRegistry) At(index int) *Builtin {
+	if index < 0 || index >= len(r.builtins) {
+		return nil
+	}
+
+	return r.builtins[index]
+}
+
+func (r *BuiltinRegistry) Name(index int) string {
+	if index < 0 || index >= len(r.names) {
+		return ""
+	}
+
+	return r.names[index]
+}
+
+func (r *BuiltinRegistry) Names() []string {
+	names := make([]string, 0, len(r.index))
+	for name := range r.index {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,282 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var Builtins = []struct {
+	Name    string
+	Builtin *Builtin
+}{
+	{
+		"len",
+		&Builtin{
+			Fn: func(args ...Object) Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. want=1, got=%d", len(args))
+				}
+
+				switch arg := args[0].(type) {
+				case *String:
+					return &Integer{Value: int64(len(arg.Value))}
+				case *Array:
+					return &Integer{Value: int64(len(arg.Elements))}
+				default:
+					return newError("argument to `len` not supported. got %s", args[0].Type())
+				}
+			},
+		},
+	},
+	{
+		"first",
+		&Builtin{
+			Fn: func(args ...Object) Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. want=1, got=%d", len(args))
+				}
+
+				switch arg := args[0].(type) {
+				case *String:
+					if len(arg.Value) > 0 {
+						return &String{Value: arg.Value[0:1]}
+					}
+					return NULL
+				case *Array:
+					if len(arg.Elements) > 0 {
+						return arg.Elements[0]
+					}
+					return NULL
+				default:
+					return newError("argument to `first` not supported. got %s", args[0].Type())
+				}
+			},
+		},
+	},
+	{
+		"last",
+		&Builtin{
+			Fn: func(args ...Object) Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. want=1, got=%d", len(args))
+				}
+
+				switch arg := args[0].(type) {
+				case *String:
+					if len(arg.Value) > 0 {
+						return &String{Value: arg.Value[len(arg.Value)-1 : len(arg.Value)]}
+					}
+					return NULL
+				case *Array:
+					if len(arg.Elements) > 0 {
+						return arg.Elements[len(arg.Elements)-1]
+					}
+					return NULL
+				default:
+					return newError("argument to `last` not supported. got %s", args[0].Type())
+				}
+			},
+		},
+	},
+	{
+		"rest",
+		&Builtin{
+			Fn: func(args ...Object) Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. want=1, got=%d", len(args))
+				}
+
+				switch arg := args[0].(type) {
+				case *String:
+					if len(arg.Value) > 1 {
+						return &String{Value: arg.Value[1:len(arg.Value)]}
+					}
+					return &String{Value: ""}
+				case *Array:
+					if len(arg.Elements) > 0 {
+						newElements := make([]Object, len(arg.Elements)-1, len(arg.Elements)-1)
+						copy(newElements, arg.Elements[1:len(arg.Elements)])
+						return &Array{Elements: newElements}
+					}
+					return &Array{Elements: []Object{}}
+				default:
+					return newError("argument to `last` not supported. got %s", args[0].Type())
+				}
+			},
+		},
+	},
+	{
+		"push",
+		&Builtin{
+			Fn: func(args ...Object) Object {
+				if len(args) < 2 {
+					return newError("wrong number of arguments. want>=2, got=%d", len(args))
+				}
+
+				switch dst := args[0].(type) {
+				case *String:
+					elements := []string{dst.Value}
+					for ix, arg := range args[1:] {
+						switch arg := arg.(type) {
+						case *String:
+							elements = append(elements, arg.Value)
+						default:
+							return newError("argument %d not supported for dst type String. got %s", ix, arg.Type())
+						}
+					}
+					return &String{Value: strings.Join(elements, "")}
+				case *Array:
+					newElements := make([]Object, len(dst.Elements))
+					copy(newElements, dst.Elements)
+					for _, arg := range args[1:] {
+						newElements = append(newElements, arg)
+					}
+
+					return &Array{Elements: newElements}
+				default:
+					return newError("first argument to `push` not supported. got %s", args[0].Type())
+				}
+			},
+		},
+	},
+	{
+		"int",
+		&Builtin{
+			Fn: func(args ...Object) Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. want=1, got=%d", len(args))
+				}
+
+				switch arg := args[0].(type) {
+				case *Integer:
+					return arg
+				case *Float:
+					if math.IsNaN(arg.Value) || arg.Value < math.MinInt64 || arg.Value >= math.MaxInt64 {
+						return newError("cannot convert %s to INTEGER", arg.Inspect())
+					}
+					return &Integer{Value: int64(arg.Value)}
+				case *String:
+					value, err := strconv.ParseInt(strings.TrimSpace(arg.Value), 0, 64)
+					if err != nil {
+						return newError("could not parse %q as integer", arg.Value)
+					}
+					return &Integer{Value: value}
+				default:
+					return newError("argument to `int` not supported. got %s", args[0].Type())
+				}
+			},
+		},
+	},
+	{
+		"float",
+		&Builtin{
+			Fn: func(args ...Object) Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. want=1, got=%d", len(args))
+				}
+
+				switch arg := args[0].(type) {
+				case *Integer:
+					return &Float{Value: float64(arg.Value)}
+				case *Float:
+					return arg
+				case *String:
+					value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+					if err != nil {
+						return newError("could not parse %q as float", arg.Value)
+					}
+					return &Float{Value: value}
+				default:
+					return newError("argument to `float` not supported. got %s", args[0].Type())
+				}
+			},
+		},
+	},
+	{
+		"abs",
+		&Builtin{
+			Fn: func(args ...Object) Object {
+				if len(args) != 1 {
+					return newError("wrong number of arguments. want=1, got=%d", len(args))
+				}
+
+				switch arg := args[0].(type) {
+				case *Integer:
+					if arg.Value < 0 {
+						return &Integer{Value: -arg.Value}
+					}
+					return arg
+				case *Float:
+					return &Float{Value: math.Abs(arg.Value)}
+				default:
+					return newError("argument to `abs` not supported. got %s", args[0].Type())
+				}
+			},
+		},
+	},
+	{
+		"floor",
+		&Builtin{
+			Fn: func(args ...Object) Object {
+				return roundFloat("floor", math.Floor, args)
+			},
+		},
+	},
+	{
+		"ceil",
+		&Builtin{
+			Fn: func(args ...Object) Object {
+				return roundFloat("ceil", math.Ceil, args)
+			},
+		},
+	},
+	{
+		"round",
+		&Builtin{
+			Fn: func(args ...Object) Object {
+				if len(args) != 2 {
+					return roundFloat("round", math.Round, args)
+				}
+
+				places, ok := args[1].(*Integer)
+				if !ok {
+					return newError("second argument to `round` must be INTEGER. got %s", args[1].Type())
+				}
+
+				scale := math.Pow(10, float64(places.Value))
+				return roundFloat("round", func(x float64) float64 { return math.Round(x*scale) / scale }, args[:1])
+			},
+		},
+	},
+}
+
+func GetBuiltinByName(name string) *Builtin {
+	for _, def := range Builtins {
+		if def.Name == name {
+			return def.Builtin
+		}
+	}
+
+	return nil
+}
+
+func roundFloat(name string, round func(float64) float64, args []Object) Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. want=1, got=%d", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *Integer:
+		return arg
+	case *Float:
+		return &Float{Value: round(arg.Value)}
+	default:
+		return newError("argument to `%s` not supported. got %s", name, args[0].Type())
+	}
+}
+
+func newError(format string, a ...interface{}) *Error {
+	return &Error{Kind: ARGUMENT_ERROR, Message: fmt.Sprintf(format, a...)}
+}
@@ -0,0 +1,11 @@
+package object
+
+import "fmt"
+
+type Closure struct {
+	Fn   *CompiledFunction
+	Free []*Object
+}
+
+func (c *Closure) Type() ObjectType { return FUNCTION_OBJ }
+func (c *Closure) Inspect() string  { return fmt.Sprintf("Closure[%p]", c) }
@@ -0,0 +1,35 @@
+package object
+
+import (
+	"fmt"
+
+	"monkeylang/code"
+	"monkeylang/token"
+)
+
+type Capture struct {
+	Name  string
+	Local bool
+	Index int
+}
+
+type CompiledFunction struct {
+	Name          string
+	Instructions  code.Instructions
+	Positions     map[int]token.Position
+	NumLocals     int
+	NumParameters int
+	NumDefaults   int
+	Rest          bool
+	LocalNames    []string
+	Captures      []Capture
+}
+
+func (cf *CompiledFunction) Type() ObjectType { return COMPILED_FUNCTION_OBJ }
+func (cf *CompiledFunction) Inspect() string {
+	return fmt.Sprintf("CompiledFunction[%p]", cf)
+}
+
+func (cf *CompiledFunction) CheckArity(got int) *Error {
+	return CheckArity(cf.NumParameters-cf.NumDefaults, cf.NumDefaults, cf.Rest, got)
+}
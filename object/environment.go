@@ -1,13 +1,37 @@
 package object
 
+const DEFAULT_MAX_RECURSION_DEPTH = 10000
+
 func NewEnvironment() *Environment {
+	return NewEnvironmentWithBuiltins(NewBuiltinRegistry())
+}
+
+func NewEnvironmentWithBuiltins(builtins *BuiltinRegistry) *Environment {
 	s := make(map[string]Object)
-	return &Environment{store: s, outer: nil}
+	return &Environment{store: s, outer: nil, runtime: &runtime{builtins: builtins, maxDepth: DEFAULT_MAX_RECURSION_DEPTH}}
 }
 
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store   map[string]Object
+	outer   *Environment
+	runtime *runtime
+}
+
+type runtime struct {
+	builtins *BuiltinRegistry
+	limiter  *Limiter
+	depth    int
+	maxDepth int
+}
+
+func (e *Environment) IsLocal(name string) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			return env.outer != nil
+		}
+	}
+
+	return false
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
@@ -25,9 +49,58 @@ func (e *Environment) Set(name string, val Object) Object {
 	return val
 }
 
-func NewEnclosedEnvironment(outer *Environment) *Environment {
-	env := NewEnvironment()
-	env.outer = outer
+func (e *Environment) Assign(name string, val Object) (Object, bool) {
+	if _, ok := e.store[name]; ok {
+		e.store[name] = val
+		return val, true
+	}
+
+	if e.outer != nil {
+		return e.outer.Assign(name, val)
+	}
+
+	return nil, false
+}
+
+func (e *Environment) Builtins() *BuiltinRegistry {
+	return e.runtime.builtins
+}
+
+func (e *Environment) Builtin(name string) (*Builtin, bool) {
+	if e.runtime.builtins == nil {
+		return nil, false
+	}
+
+	return e.runtime.builtins.Get(name)
+}
+
+func (e *Environment) Limiter() *Limiter {
+	return e.runtime.limiter
+}
+
+func (e *Environment) SetLimiter(limiter *Limiter) {
+	e.runtime.limiter = limiter
+}
+
+func (e *Environment) SetMaxRecursionDepth(depth int) {
+	e.runtime.maxDepth = depth
+}
 
-	return env
+func (e *Environment) EnterCall() bool {
+	if e.runtime.depth >= e.runtime.maxDepth {
+		return false
+	}
+
+	e.runtime.depth++
+
+	return true
+}
+
+func (e *Environment) LeaveCall() {
+	e.runtime.depth--
+}
+
+func NewEnclosedEnvironment(outer *Environment) *Environment {
+	s := make(map[string]Object)
+	return &Environment{store: s, outer: outer, runtime: outer.runtime}
 }
@@ -1,7 +1,40 @@
 package object
 
+import (
+	"bytes"
+	"fmt"
+
+	"monkeylang/token"
+)
+
+const TRACEBACK_REPEAT_LIMIT = 3
+
+const (
+	ERROR               = "Error"
+	TYPE_ERROR          = "TypeError"
+	NAME_ERROR          = "NameError"
+	ARGUMENT_ERROR      = "ArgumentError"
+	INDEX_ERROR         = "IndexError"
+	ZERO_DIVISION_ERROR = "ZeroDivisionError"
+	LIMIT_ERROR         = "LimitError"
+	RECURSION_ERROR     = "RecursionError"
+	MACRO_ERROR         = "MacroError"
+)
+
 type Error struct {
-	Message string
+	Kind     string
+	Message  string
+	Position token.Position
+	Value    Object
+	Stack    []StackFrame
+}
+
+func NewThrownError(value Object) *Error {
+	if errorValue, ok := value.(*ErrorValue); ok {
+		return errorValue.Error
+	}
+
+	return &Error{Kind: ERROR, Message: value.Inspect(), Value: value}
 }
 
 func (e *Error) Type() ObjectType {
@@ -9,5 +42,92 @@ func (e *Error) Type() ObjectType {
 }
 
 func (e *Error) Inspect() string {
+	if e.Position.IsValid() {
+		return "ERROR: " + e.Position.String() + ": " + e.Message
+	}
+
 	return "ERROR: " + e.Message
 }
+
+func (e *Error) Traceback() string {
+	if len(e.Stack) == 0 {
+		return e.Inspect()
+	}
+
+	var out bytes.Buffer
+
+	out.WriteString("Traceback (most recent call last):\n")
+
+	caller := "<main>"
+	previous := ""
+	repeated := 0
+
+	for i := len(e.Stack) - 1; i >= 0; i-- {
+		frame := e.Stack[i]
+		if frame.TailCalls > 0 {
+			writeRepeated(&out, repeated)
+			repeated, previous = 0, ""
+			writeTailCalls(&out, frame.TailCalls)
+			caller = frame.CallerName()
+		}
+
+		line := "  at " + frame.Position.String() + " in " + caller + ": " + frame.Call() + "\n"
+		caller = frame.FunctionName()
+
+		if line == previous {
+			repeated++
+			if repeated >= TRACEBACK_REPEAT_LIMIT {
+				continue
+			}
+		} else {
+			writeRepeated(&out, repeated)
+			repeated = 0
+		}
+
+		out.WriteString(line)
+		previous = line
+	}
+	writeRepeated(&out, repeated)
+
+	out.WriteString("  at " + e.Position.String() + " in " + caller + "\n")
+	out.WriteString(e.Inspect())
+
+	return out.String()
+}
+
+func writeRepeated(out *bytes.Buffer, repeated int) {
+	if repeated < TRACEBACK_REPEAT_LIMIT {
+		return
+	}
+
+	fmt.Fprintf(out, "  [Previous line repeated %d more times]\n", repeated-TRACEBACK_REPEAT_LIMIT+1)
+}
+
+func writeTailCalls(out *bytes.Buffer, tailCalls int) {
+	if tailCalls == 1 {
+		out.WriteString("  [1 tail call elided]\n")
+		return
+	}
+
+	fmt.Fprintf(out, "  [%d tail calls elided]\n", tailCalls)
+}
+
+func (e *Error) KindName() string {
+	if e.Kind == "" {
+		return ERROR
+	}
+
+	return e.Kind
+}
+
+func (e *Error) Catchable() bool {
+	return e.Kind != LIMIT_ERROR
+}
+
+func (e *Error) CaughtValue() Object {
+	if e.Value != nil {
+		return e.Value
+	}
+
+	return &ErrorValue{Error: e}
+}
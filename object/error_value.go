@@ -0,0 +1,36 @@
+package object
+
+type ErrorValue struct {
+	Error *Error
+}
+
+func (ev *ErrorValue) Type() ObjectType { return ERROR_VALUE_OBJ }
+func (ev *ErrorValue) Inspect() string {
+	if ev.Error.Position.IsValid() {
+		return ev.Error.KindName() + " at " + ev.Error.Position.String() + ": " + ev.Error.Message
+	}
+
+	return ev.Error.KindName() + ": " + ev.Error.Message
+}
+
+func (ev *ErrorValue) Field(name string) (Object, bool) {
+	switch name {
+	case "message":
+		return &String{Value: ev.Error.Message}, true
+	case "kind":
+		return &String{Value: ev.Error.KindName()}, true
+	case "position":
+		if !ev.Error.Position.IsValid() {
+			return NULL, true
+		}
+		return &String{Value: ev.Error.Position.String()}, true
+	case "stack":
+		frames := make([]Object, len(ev.Error.Stack))
+		for i, frame := range ev.Error.Stack {
+			frames[len(frames)-1-i] = frame.Hash()
+		}
+		return &Array{Elements: frames}, true
+	default:
+		return nil, false
+	}
+}
@@ -8,7 +8,10 @@ import (
 )
 
 type Function struct {
+	Name       string
 	Parameters []*ast.Identifier
+	Defaults   []ast.Expression
+	Rest       *ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
 }
@@ -17,13 +20,32 @@ func (f *Function) Type() ObjectType {
 	return FUNCTION_OBJ
 }
 
+func (f *Function) CheckArity(got int) *Error {
+	optional := 0
+	for _, def := range f.Defaults {
+		if def != nil {
+			optional++
+		}
+	}
+
+	return CheckArity(len(f.Parameters)-optional, optional, f.Rest != nil, got)
+}
+
 func (f *Function) Inspect() string {
 	var out bytes.Buffer
 
 	params := []string{}
 
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
+	for i, p := range f.Parameters {
+		if i < len(f.Defaults) && f.Defaults[i] != nil {
+			params = append(params, p.String()+" = "+f.Defaults[i].String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+
+	if f.Rest != nil {
+		params = append(params, "..."+f.Rest.String())
 	}
 
 	out.WriteString("fn(")
@@ -0,0 +1,83 @@
+package object
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func (r *BuiltinRegistry) defineIOBuiltins() {
+	r.Define("puts", &Builtin{
+		Fn: func(args ...Object) Object {
+			for _, arg := range args {
+				if _, err := fmt.Fprintln(r.stdout, arg.Inspect()); err != nil {
+					return newIOError("could not write output: %s", err)
+				}
+			}
+
+			return NULL
+		},
+	})
+
+	r.Define("print", &Builtin{
+		Fn: func(args ...Object) Object {
+			return writeLine(r.stdout, args)
+		},
+	})
+
+	r.Define("eprint", &Builtin{
+		Fn: func(args ...Object) Object {
+			return writeLine(r.stderr, args)
+		},
+	})
+
+	r.Define("readline", &Builtin{
+		Fn: func(args ...Object) Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. want=0 or 1, got=%d", len(args))
+			}
+
+			if len(args) == 1 {
+				prompt, ok := args[0].(*String)
+				if !ok {
+					return newError("argument to `readline` must be STRING. got %s", args[0].Type())
+				}
+
+				if _, err := io.WriteString(r.stdout, prompt.Value); err != nil {
+					return newIOError("could not write output: %s", err)
+				}
+			}
+
+			line, err := r.stdin.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return newIOError("could not read input: %s", err)
+			}
+
+			if err == io.EOF && line == "" {
+				return NULL
+			}
+
+			line = strings.TrimSuffix(line, "\n")
+			line = strings.TrimSuffix(line, "\r")
+
+			return &String{Value: line}
+		},
+	})
+}
+
+func writeLine(w io.Writer, args []Object) Object {
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = arg.Inspect()
+	}
+
+	if _, err := fmt.Fprintln(w, strings.Join(values, " ")); err != nil {
+		return newIOError("could not write output: %s", err)
+	}
+
+	return NULL
+}
+
+func newIOError(format string, a ...interface{}) *Error {
+	return &Error{Kind: ERROR, Message: fmt.Sprintf(format, a...)}
+}
@@ -0,0 +1,67 @@
+package object
+
+import "fmt"
+
+type Iterator struct {
+	keys   []Object
+	values []Object
+	keyed  bool
+	index  int
+}
+
+func NewIterator(obj Object) (*Iterator, bool) {
+	switch obj := obj.(type) {
+	case *Array:
+		values := make([]Object, len(obj.Elements))
+		copy(values, obj.Elements)
+		return &Iterator{keys: indices(len(values)), values: values}, true
+	case *String:
+		values := make([]Object, len(obj.Value))
+		for i := range obj.Value {
+			values[i] = &String{Value: obj.Value[i : i+1]}
+		}
+		return &Iterator{keys: indices(len(values)), values: values}, true
+	case *Hash:
+		pairs := obj.SortedPairs()
+		keys := make([]Object, len(pairs))
+		values := make([]Object, len(pairs))
+		for i, pair := range pairs {
+			keys[i] = pair.Key
+			values[i] = pair.Value
+		}
+		return &Iterator{keys: keys, values: values, keyed: true}, true
+	default:
+		return nil, false
+	}
+}
+
+func indices(n int) []Object {
+	keys := make([]Object, n)
+	for i := range keys {
+		keys[i] = &Integer{Value: int64(i)}
+	}
+
+	return keys
+}
+
+func (it *Iterator) Type() ObjectType { return ITERATOR_OBJ }
+func (it *Iterator) Inspect() string  { return fmt.Sprintf("Iterator[%p]", it) }
+
+func (it *Iterator) Next() (key, value Object, ok bool) {
+	if it.index >= len(it.values) {
+		return nil, nil, false
+	}
+
+	key, value = it.keys[it.index], it.values[it.index]
+	it.index++
+
+	return key, value, true
+}
+
+func (it *Iterator) Item(key, value Object) Object {
+	if it.keyed {
+		return key
+	}
+
+	return value
+}
@@ -0,0 +1,122 @@
+package object
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const limiterCheckInterval = 1024
+
+type Limits struct {
+	MaxSteps       int64
+	MaxCallDepth   int
+	MaxAllocations int64
+	Timeout        time.Duration
+}
+
+type Limiter struct {
+	ctx      context.Context
+	limits   Limits
+	deadline time.Time
+
+	steps       int64
+	depth       int
+	allocations int64
+}
+
+func NewLimiter(ctx context.Context, limits Limits) *Limiter {
+	if limits == (Limits{}) && ctx.Done() == nil {
+		return nil
+	}
+
+	l := &Limiter{ctx: ctx, limits: limits}
+	if limits.Timeout > 0 {
+		l.deadline = time.Now().Add(limits.Timeout)
+	}
+
+	return l
+}
+
+func (l *Limiter) Step() *Error {
+	if l == nil {
+		return nil
+	}
+
+	l.steps++
+	if l.limits.MaxSteps > 0 && l.steps > l.limits.MaxSteps {
+		return newLimitError("step limit exceeded: %d steps", l.limits.MaxSteps)
+	}
+
+	if l.steps%limiterCheckInterval == 0 {
+		return l.checkTime()
+	}
+
+	return nil
+}
+
+func (l *Limiter) Enter() *Error {
+	if l == nil {
+		return nil
+	}
+
+	if l.limits.MaxCallDepth > 0 && l.depth >= l.limits.MaxCallDepth {
+		return newLimitError("call depth limit exceeded: %d calls", l.limits.MaxCallDepth)
+	}
+
+	l.depth++
+
+	return nil
+}
+
+func (l *Limiter) Leave() {
+	if l == nil || l.depth == 0 {
+		return
+	}
+
+	l.depth--
+}
+
+func (l *Limiter) Allocate(obj Object) *Error {
+	if l == nil {
+		return nil
+	}
+
+	l.allocations += allocationSize(obj)
+	if l.limits.MaxAllocations > 0 && l.allocations > l.limits.MaxAllocations {
+		return newLimitError("allocation limit exceeded: %d objects", l.limits.MaxAllocations)
+	}
+
+	return nil
+}
+
+func (l *Limiter) checkTime() *Error {
+	if !l.deadline.IsZero() && time.Now().After(l.deadline) {
+		return newLimitError("time limit exceeded: %s", l.limits.Timeout)
+	}
+
+	if err := l.ctx.Err(); err != nil {
+		return newLimitError("execution canceled: %s", err)
+	}
+
+	return nil
+}
+
+func allocationSize(obj Object) int64 {
+	switch obj := obj.(type) {
+	case *String:
+		return 1 + int64(len(obj.Value))/64
+	case *Function, *Closure:
+		return 1
+	case *Array:
+		return 1 + int64(len(obj.Elements))
+	case *Hash:
+		return 1 + int64(len(obj.Pairs))
+	default:
+		return 0
+	}
+}
+
+func newLimitError(format string, a ...interface{}) *Error {
+	return &Error{Kind: LIMIT_ERROR, Message: fmt.Sprintf(format, a...)}
+}
@@ -0,0 +1,17 @@
+package object
+
+import "monkeylang/token"
+
+type Break struct {
+	Position token.Position
+}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+
+type Continue struct {
+	Position token.Position
+}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
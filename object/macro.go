@@ -8,6 +8,7 @@ import (
 
 type Macro struct {
 	Parameters []*ast.Identifier
+	Rest       *ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
 }
@@ -21,6 +22,10 @@ func (m *Macro) Inspect() string {
 		params = append(params, p.String())
 	}
 
+	if m.Rest != nil {
+		params = append(params, "..."+m.Rest.String())
+	}
+
 	out.WriteString("macro")
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
@@ -30,3 +35,7 @@ func (m *Macro) Inspect() string {
 
 	return out.String()
 }
+
+func (m *Macro) CheckArity(got int) *Error {
+	return CheckArity(len(m.Parameters), 0, m.Rest != nil, got)
+}
@@ -0,0 +1,98 @@
+package object
+
+import (
+	"fmt"
+
+	"monkeylang/ast"
+	"monkeylang/token"
+)
+
+func (r *BuiltinRegistry) defineMacroBuiltins() {
+	r.Define("gensym", &Builtin{
+		Fn: func(args ...Object) Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. want=0 or 1, got=%d", len(args))
+			}
+
+			prefix := "g"
+			if len(args) == 1 {
+				str, ok := args[0].(*String)
+				if !ok {
+					return newError("argument to `gensym` must be STRING. got %s", args[0].Type())
+				}
+
+				prefix = str.Value
+			}
+
+			name := r.Gensym(prefix)
+
+			return &Quote{Node: &ast.Identifier{
+				Token: token.Token{Type: token.IDENT, Literal: name},
+				Value: name,
+			}}
+		},
+	})
+
+	r.Define("kind", &Builtin{
+		Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. want=1, got=%d", len(args))
+			}
+
+			quote, ok := args[0].(*Quote)
+			if !ok {
+				return newError("argument to `kind` must be QUOTE. got %s", args[0].Type())
+			}
+
+			return &String{Value: nodeKind(quote.Node)}
+		},
+	})
+
+	r.Define("fields", &Builtin{
+		Fn: func(args ...Object) Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. want=1, got=%d", len(args))
+			}
+
+			quote, ok := args[0].(*Quote)
+			if !ok {
+				return newError("argument to `fields` must be QUOTE. got %s", args[0].Type())
+			}
+
+			return nodeFields(quote.Node)
+		},
+	})
+
+	r.Define("node", &Builtin{
+		Fn: func(args ...Object) Object {
+			if len(args) < 1 || len(args) > 2 {
+				return newError("wrong number of arguments. want=1 or 2, got=%d", len(args))
+			}
+
+			kind, ok := args[0].(*String)
+			if !ok {
+				return newError("first argument to `node` must be STRING. got %s", args[0].Type())
+			}
+
+			fields := &Hash{Pairs: map[HashKey]HashPair{}}
+			if len(args) == 2 {
+				fields, ok = args[1].(*Hash)
+				if !ok {
+					return newError("second argument to `node` must be HASH. got %s", args[1].Type())
+				}
+			}
+
+			node, err := buildNode(kind.Value, fields)
+			if err != nil {
+				return err
+			}
+
+			return &Quote{Node: node}
+		},
+	})
+}
+
+func (r *BuiltinRegistry) Gensym(prefix string) string {
+	r.gensyms++
+	return fmt.Sprintf("%s__%d", prefix, r.gensyms)
+}
@@ -1,6 +1,15 @@
 package object
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"monkeylang/token"
+)
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "Hello World"}
@@ -20,3 +29,289 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("strings with different content have same hash keys")
 	}
 }
+
+func TestFloatHashKey(t *testing.T) {
+	half1 := &Float{Value: 0.5}
+	half2 := &Float{Value: 0.5}
+	quarter := &Float{Value: 0.25}
+
+	if half1.HashKey() != half2.HashKey() {
+		t.Errorf("floats with same value have different hash keys")
+	}
+
+	if half1.HashKey() == quarter.HashKey() {
+		t.Errorf("floats with different values have same hash keys")
+	}
+
+	if (&Float{Value: 2}).HashKey() != (&Integer{Value: 2}).HashKey() {
+		t.Errorf("integral float and equal integer have different hash keys")
+	}
+
+	if (&Float{Value: 0}).HashKey() != (&Float{Value: math.Copysign(0, -1)}).HashKey() {
+		t.Errorf("positive and negative zero have different hash keys")
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{3.14, "3.14"},
+		{2, "2.0"},
+		{-0.5, "-0.5"},
+		{1e-9, "1e-09"},
+		{1e21, "1e+21"},
+		{math.Inf(1), "+Inf"},
+		{math.NaN(), "NaN"},
+	}
+
+	for _, tt := range tests {
+		if got := (&Float{Value: tt.value}).Inspect(); got != tt.expected {
+			t.Errorf("Inspect() wrong. want=%q, got=%q", tt.expected, got)
+		}
+	}
+}
+
+func TestErrorTraceback(t *testing.T) {
+	err := &Error{
+		Message:  "boom",
+		Position: token.Position{Line: 1, Column: 10},
+		Stack: []StackFrame{
+			{Function: "f", Position: token.Position{Line: 1, Column: 5}, Arguments: 1},
+			{Function: "f", Position: token.Position{Line: 1, Column: 5}, Arguments: 1},
+			{Function: "f", Position: token.Position{Line: 1, Column: 5}, Arguments: 1},
+			{Function: "f", Position: token.Position{Line: 1, Column: 5}, Arguments: 1},
+			{Function: "f", Position: token.Position{Line: 1, Column: 5}, Arguments: 1},
+			{Function: "", Position: token.Position{Line: 2, Column: 1}, Arguments: 0},
+		},
+	}
+
+	expected := `Traceback (most recent call last):
+  at 2:1 in <main>: <anonymous>(0 args)
+  at 1:5 in <anonymous>: f(1 arg)
+  at 1:5 in f: f(1 arg)
+  at 1:5 in f: f(1 arg)
+  at 1:5 in f: f(1 arg)
+  [Previous line repeated 1 more times]
+  at 1:10 in f
+ERROR: 1:10: boom`
+
+	if err.Traceback() != expected {
+		t.Errorf("wrong traceback. expected=\n%s\ngot=\n%s", expected, err.Traceback())
+	}
+
+	tailCalled := &Error{
+		Message:  "boom",
+		Position: token.Position{Line: 1, Column: 24},
+		Stack: []StackFrame{
+			{Function: "inner", Position: token.Position{Line: 3, Column: 3}, Arguments: 2, Caller: "outer", TailCalls: 1},
+		},
+	}
+
+	expected = `Traceback (most recent call last):
+  [1 tail call elided]
+  at 3:3 in outer: inner(2 args)
+  at 1:24 in inner
+ERROR: 1:24: boom`
+
+	if tailCalled.Traceback() != expected {
+		t.Errorf("wrong traceback with elided tail calls. expected=\n%s\ngot=\n%s", expected, tailCalled.Traceback())
+	}
+
+	withoutStack := &Error{Message: "boom", Position: token.Position{Line: 1, Column: 10}}
+	if withoutStack.Traceback() != withoutStack.Inspect() {
+		t.Errorf("traceback without stack should equal Inspect(). got=%q", withoutStack.Traceback())
+	}
+}
+
+func TestBuiltinRegistry(t *testing.T) {
+	r := NewEmptyBuiltinRegistry()
+	first := &Builtin{}
+	second := &Builtin{}
+
+	if index := r.Define("b", first); index != 0 {
+		t.Errorf("wrong index for b. want=0, got=%d", index)
+	}
+	if index := r.Define("a", first); index != 1 {
+		t.Errorf("wrong index for a. want=1, got=%d", index)
+	}
+	if index := r.Define("b", second); index != 0 {
+		t.Errorf("override allocated a new index. want=0, got=%d", index)
+	}
+
+	if builtin, ok := r.Get("b"); !ok || builtin != second {
+		t.Errorf("b not overridden. got=%p (%t)", builtin, ok)
+	}
+
+	if names := r.Names(); len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("wrong names. got=%v", names)
+	}
+
+	if !r.Remove("b") {
+		t.Errorf("Remove(b) returned false")
+	}
+	if r.Remove("b") {
+		t.Errorf("Remove(b) returned true for a removed builtin")
+	}
+
+	if _, ok := r.Get("b"); ok {
+		t.Errorf("removed builtin still defined")
+	}
+	if r.At(0) != nil || r.Name(0) != "b" {
+		t.Errorf("removed slot not cleared. got=%p %q", r.At(0), r.Name(0))
+	}
+
+	if index := r.Define("c", first); index != 2 {
+		t.Errorf("wrong index for c. want=2, got=%d", index)
+	}
+}
+
+func TestBuiltinRegistriesAreIndependent(t *testing.T) {
+	sandboxed := NewBuiltinRegistry()
+	sandboxed.Remove("puts")
+
+	if _, ok := NewBuiltinRegistry().Get("puts"); !ok {
+		t.Errorf("removing puts from one registry affected another")
+	}
+
+	for _, name := range builtinNames(t, sandboxed) {
+		if name == "puts" {
+			t.Errorf("builtins() lists removed builtin puts")
+		}
+	}
+}
+
+func builtinNames(t *testing.T, r *BuiltinRegistry) []string {
+	builtin, ok := r.Get("builtins")
+	if !ok {
+		t.Fatalf("builtins not defined")
+	}
+
+	array, ok := builtin.Fn().(*Array)
+	if !ok {
+		t.Fatalf("builtins() did not return an Array")
+	}
+
+	names := []string{}
+	for _, element := range array.Elements {
+		names = append(names, element.(*String).Value)
+	}
+
+	return names
+}
+
+func TestIOBuiltins(t *testing.T) {
+	r := NewBuiltinRegistry()
+
+	var stdout, stderr bytes.Buffer
+	r.SetStdout(&stdout)
+	r.SetStderr(&stderr)
+	r.SetStdin(strings.NewReader("first\r\nsecond\nlast"))
+
+	call := func(name string, args ...Object) Object {
+		builtin, ok := r.Get(name)
+		if !ok {
+			t.Fatalf("builtin %s not defined", name)
+		}
+
+		return builtin.Fn(args...)
+	}
+
+	call("puts", &String{Value: "a"}, &Integer{Value: 1})
+	call("print", &String{Value: "b"}, &Integer{Value: 2}, NULL)
+	call("print")
+	call("eprint", &String{Value: "oops"})
+
+	if stdout.String() != "a\n1\nb 2 null\n\n" {
+		t.Errorf("wrong stdout. got=%q", stdout.String())
+	}
+
+	if stderr.String() != "oops\n" {
+		t.Errorf("wrong stderr. got=%q", stderr.String())
+	}
+
+	stdout.Reset()
+
+	expected := []Object{
+		&String{Value: "first"},
+		&String{Value: "second"},
+		&String{Value: "last"},
+		NULL,
+	}
+
+	for i, want := range expected {
+		got := call("readline", &String{Value: "> "})
+		if got.Inspect() != want.Inspect() || got.Type() != want.Type() {
+			t.Errorf("readline %d wrong result. want=%s, got=%s", i, want.Inspect(), got.Inspect())
+		}
+	}
+
+	if stdout.String() != "> > > > " {
+		t.Errorf("readline did not write prompts. got=%q", stdout.String())
+	}
+
+	errorTests := []struct {
+		name     string
+		args     []Object
+		expected string
+	}{
+		{"readline", []Object{&Integer{Value: 1}}, "argument to `readline` must be STRING. got INTEGER"},
+		{"readline", []Object{NULL, NULL}, "wrong number of arguments. want=0 or 1, got=2"},
+	}
+
+	for _, tt := range errorTests {
+		errObj, ok := call(tt.name, tt.args...).(*Error)
+		if !ok || errObj.Message != tt.expected {
+			t.Errorf("%s wrong error. want=%q, got=%+v", tt.name, tt.expected, errObj)
+		}
+	}
+
+	r.SetStdout(failingWriter{})
+	errObj, ok := call("print", &String{Value: "x"}).(*Error)
+	if !ok || errObj.Message != "could not write output: closed" {
+		t.Errorf("print to failing writer wrong result. got=%+v", errObj)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("closed")
+}
+
+func TestLimiter(t *testing.T) {
+	if l := NewLimiter(context.Background(), Limits{}); l != nil {
+		t.Fatalf("expected no limiter without limits, got=%+v", l)
+	}
+
+	var unlimited *Limiter
+	if unlimited.Step() != nil || unlimited.Enter() != nil || unlimited.Allocate(&Array{}) != nil {
+		t.Errorf("nil limiter enforced a limit")
+	}
+	unlimited.Leave()
+
+	l := NewLimiter(context.Background(), Limits{MaxCallDepth: 2, MaxAllocations: 4})
+
+	if l.Enter() != nil || l.Enter() != nil {
+		t.Fatalf("limiter rejected calls within the depth limit")
+	}
+	if err := l.Enter(); err == nil || err.Kind != LIMIT_ERROR {
+		t.Errorf("limiter allowed a call beyond the depth limit. got=%+v", err)
+	}
+	l.Leave()
+	if l.Enter() != nil {
+		t.Errorf("limiter rejected a call after leaving")
+	}
+
+	if l.Allocate(&Integer{Value: 1}) != nil || l.Allocate(&Array{Elements: []Object{NULL, NULL, NULL}}) != nil {
+		t.Fatalf("limiter rejected allocations within the limit")
+	}
+	if err := l.Allocate(&String{Value: "ab"}); err == nil || err.Message != "allocation limit exceeded: 4 objects" {
+		t.Errorf("wrong allocation error. got=%+v", err)
+	}
+
+	if err := (&Error{Kind: LIMIT_ERROR}); err.Catchable() {
+		t.Errorf("limit errors must not be catchable")
+	}
+}
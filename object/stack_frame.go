@@ -0,0 +1,54 @@
+package object
+
+import (
+	"fmt"
+
+	"monkeylang/token"
+)
+
+type StackFrame struct {
+	Function  string
+	Position  token.Position
+	Arguments int
+	Caller    string
+	TailCalls int
+}
+
+func (sf StackFrame) FunctionName() string {
+	return functionName(sf.Function)
+}
+
+func (sf StackFrame) CallerName() string {
+	return functionName(sf.Caller)
+}
+
+func functionName(name string) string {
+	if name == "" {
+		return "<anonymous>"
+	}
+
+	return name
+}
+
+func (sf StackFrame) Call() string {
+	if sf.Arguments == 1 {
+		return fmt.Sprintf("%s(1 arg)", sf.FunctionName())
+	}
+
+	return fmt.Sprintf("%s(%d args)", sf.FunctionName(), sf.Arguments)
+}
+
+func (sf StackFrame) Hash() *Hash {
+	fields := []HashPair{
+		{Key: &String{Value: "function"}, Value: &String{Value: sf.FunctionName()}},
+		{Key: &String{Value: "position"}, Value: &String{Value: sf.Position.String()}},
+		{Key: &String{Value: "arguments"}, Value: &Integer{Value: int64(sf.Arguments)}},
+	}
+
+	pairs := make(map[HashKey]HashPair)
+	for _, pair := range fields {
+		pairs[pair.Key.(*String).HashKey()] = pair
+	}
+
+	return &Hash{Pairs: pairs}
+}
@@ -0,0 +1,30 @@
+package parser
+
+import "monkeylang/token"
+
+type ErrorCode string
+
+const (
+	UNEXPECTED_TOKEN   ErrorCode = "UnexpectedToken"
+	ILLEGAL_TOKEN      ErrorCode = "IllegalToken"
+	MISSING_EXPRESSION ErrorCode = "MissingExpression"
+	INVALID_NUMBER     ErrorCode = "InvalidNumber"
+	INVALID_ASSIGNMENT ErrorCode = "InvalidAssignment"
+	INVALID_PARAMETER  ErrorCode = "InvalidParameter"
+)
+
+type Error struct {
+	Code     ErrorCode
+	Message  string
+	Position token.Position
+	Expected []token.TokenType
+	Found    token.Token
+}
+
+func (e *Error) Error() string {
+	if e.Position.IsValid() {
+		return e.Position.String() + ": " + e.Message
+	}
+
+	return e.Message
+}
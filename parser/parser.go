@@ -12,6 +12,9 @@ import (
 const (
 	_int = iota
 	LOWEST
+	ASSIGNMENT
+	LOGICAL_OR
+	LOGICAL_AND
 	EQUALS
 	LESSGREATER
 	SUM
@@ -22,16 +25,27 @@ const (
 )
 
 var precendences = map[token.TokenType]int{
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
+	token.ASSIGN:          ASSIGNMENT,
+	token.PLUS_ASSIGN:     ASSIGNMENT,
+	token.MINUS_ASSIGN:    ASSIGNMENT,
+	token.ASTERISK_ASSIGN: ASSIGNMENT,
+	token.SLASH_ASSIGN:    ASSIGNMENT,
+	token.PERCENT_ASSIGN:  ASSIGNMENT,
+	token.OR:              LOGICAL_OR,
+	token.AND:             LOGICAL_AND,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LT_EQ:           LESSGREATER,
+	token.GT_EQ:           LESSGREATER,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.PERCENT:         PRODUCT,
+	token.LPAREN:          CALL,
+	token.LBRACKET:        INDEX,
 }
 
 type (
@@ -48,11 +62,13 @@ type Parser struct {
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 
-	errors []string
+	errors []*Error
+	synced int
+	depth  int
 }
 
 func New(l *lexer.Lexer) *Parser {
-	p := &Parser{l: l, errors: []string{}}
+	p := &Parser{l: l, errors: []*Error{}}
 
 	p.nextToken()
 	p.nextToken()
@@ -60,6 +76,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
@@ -72,16 +89,31 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 	p.registerPrefix(token.NULL, p.parseNull)
 	p.registerPrefix(token.MACRO, p.parseMacroLiteral)
+	p.registerPrefix(token.WHILE, p.parseWhileExpression)
+	p.registerPrefix(token.FOR, p.parseForExpression)
+	p.registerPrefix(token.TRY, p.parseTryExpression)
+	p.registerPrefix(token.THROW, p.parseThrowExpression)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
 	p.registerInfix(token.MINUS, p.parseInfixExpression)
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PERCENT_ASSIGN, p.parseAssignExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 
@@ -91,6 +123,15 @@ func New(l *lexer.Lexer) *Parser {
 func (p *Parser) nextToken() {
 	p.currentToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+
+	switch p.currentToken.Type {
+	case token.LBRACE:
+		p.depth++
+	case token.RBRACE:
+		if p.depth > 0 {
+			p.depth--
+		}
+	}
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
@@ -100,6 +141,10 @@ func (p *Parser) ParseProgram() *ast.Program {
 
 	for !p.currentTokenIs(token.EOF) {
 		statement := p.parseStatement()
+		if p.recoverStatement(0) {
+			statement = nil
+		}
+
 		if statement != nil {
 			program.Statements = append(program.Statements, statement)
 		}
@@ -116,12 +161,16 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
-func (p *Parser) Errors() []string {
+func (p *Parser) Errors() []*Error {
 	return p.errors
 }
 
@@ -132,12 +181,89 @@ func (p *Parser) peekError(expectedTokenType token.TokenType) {
 		p.peekToken.Type,
 	)
 
-	p.errors = append(p.errors, msg)
+	p.addError(&Error{
+		Code:     UNEXPECTED_TOKEN,
+		Message:  msg,
+		Position: p.peekToken.Start,
+		Expected: []token.TokenType{expectedTokenType},
+		Found:    p.peekToken,
+	})
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	if t == token.ILLEGAL && isMalformedNumber(p.currentToken.Literal) {
+		p.addError(&Error{
+			Code:     INVALID_NUMBER,
+			Message:  fmt.Sprintf("malformed number literal %s", p.currentToken.Literal),
+			Position: p.currentToken.Start,
+			Found:    p.currentToken,
+		})
+		return
+	}
+
+	code := MISSING_EXPRESSION
+	if t == token.ILLEGAL {
+		code = ILLEGAL_TOKEN
+	}
+
+	p.addError(&Error{
+		Code:     code,
+		Message:  fmt.Sprintf("no prefix parse function for %s found", t),
+		Position: p.currentToken.Start,
+		Found:    p.currentToken,
+	})
+}
+
+func isMalformedNumber(literal string) bool {
+	return len(literal) > 1 && ('0' <= literal[0] && literal[0] <= '9' || literal[0] == '.')
+}
+
+func (p *Parser) errorAt(code ErrorCode, pos token.Position, msg string) {
+	p.addError(&Error{Code: code, Message: msg, Position: pos, Found: p.currentToken})
+}
+
+func (p *Parser) addError(err *Error) {
+	if len(p.errors) > p.synced {
+		return
+	}
+
+	p.errors = append(p.errors, err)
+}
+
+func (p *Parser) recoverStatement(depth int) bool {
+	if len(p.errors) == p.synced {
+		return false
+	}
+
+	p.synchronize(depth)
+	p.synced = len(p.errors)
+
+	return true
+}
+
+func (p *Parser) synchronize(depth int) {
+	for !p.currentTokenIs(token.EOF) && p.depth >= depth {
+		if p.depth == depth {
+			if p.currentTokenIs(token.SEMICOLON) || isStatementBoundary(p.peekToken.Type) && !p.strayBrace(depth) {
+				return
+			}
+		}
+
+		p.nextToken()
+	}
+}
+
+func (p *Parser) strayBrace(depth int) bool {
+	return depth == 0 && p.peekTokenIs(token.RBRACE)
+}
+
+func isStatementBoundary(t token.TokenType) bool {
+	switch t {
+	case token.EOF, token.RBRACE, token.LET, token.RETURN, token.BREAK, token.CONTINUE:
+		return true
+	default:
+		return false
+	}
 }
 
 func (p *Parser) parseLetStatement() *ast.LetStatement {
@@ -147,7 +273,13 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 		return nil
 	}
 
-	statement.Name = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+	name := &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+	if name.Value == "unquote" && p.peekTokenIs(token.LPAREN) {
+		p.nextToken()
+		statement.Unquote = p.parseCallExpression(name)
+	} else {
+		statement.Name = name
+	}
 
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
@@ -157,6 +289,10 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	statement.Value = p.parseExpression(LOWEST)
 
+	if literal, ok := statement.Value.(*ast.FunctionLiteral); ok && statement.Name != nil {
+		literal.Name = statement.Name.Value
+	}
+
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
@@ -178,6 +314,26 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return statement
 }
 
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	statement := &ast.BreakStatement{Token: p.currentToken}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return statement
+}
+
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	statement := &ast.ContinueStatement{Token: p.currentToken}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return statement
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	statement := &ast.ExpressionStatement{
 		Token:      p.currentToken,
@@ -224,7 +380,22 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	value, err := strconv.ParseInt(p.currentToken.Literal, 0, 64)
 	if err != nil {
 		msg := fmt.Sprintf("could not parse %s as integer", p.currentToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.errorAt(INVALID_NUMBER, p.currentToken.Start, msg)
+		return nil
+	}
+
+	literal.Value = value
+
+	return literal
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	literal := &ast.FloatLiteral{Token: p.currentToken}
+
+	value, err := strconv.ParseFloat(p.currentToken.Literal, 64)
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %s as float", p.currentToken.Literal)
+		p.errorAt(INVALID_NUMBER, p.currentToken.Start, msg)
 		return nil
 	}
 
@@ -260,6 +431,33 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	expression := &ast.AssignExpression{
+		Token:    p.currentToken,
+		Target:   target,
+		Operator: p.currentToken.Literal,
+	}
+
+	switch target := target.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+	case *ast.CallExpression:
+		if target.Function.TokenLiteral() != "unquote" {
+			msg := fmt.Sprintf("cannot assign to %s", target.String())
+			p.errorAt(INVALID_ASSIGNMENT, target.Pos(), msg)
+			return nil
+		}
+	default:
+		msg := fmt.Sprintf("cannot assign to %s", target.String())
+		p.errorAt(INVALID_ASSIGNMENT, target.Pos(), msg)
+		return nil
+	}
+
+	p.nextToken()
+	expression.Value = p.parseExpression(LOWEST)
+
+	return expression
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{
 		Token: p.currentToken,
@@ -312,22 +510,170 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseWhileExpression() ast.Expression {
+	expression := &ast.WhileExpression{Token: p.currentToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Body = p.parseBlockStatement()
+
+	return expression
+}
+
+func (p *Parser) parseForExpression() ast.Expression {
+	expression := &ast.ForExpression{Token: p.currentToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	expression.Value = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		expression.Key = expression.Value
+		expression.Value = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+	}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Collection = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Body = p.parseBlockStatement()
+
+	return expression
+}
+
+func (p *Parser) parseTryExpression() ast.Expression {
+	expression := &ast.TryExpression{Token: p.currentToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Block = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.CATCH) {
+		p.nextToken()
+
+		if !p.expectPeek(token.LPAREN) {
+			return nil
+		}
+
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		expression.CatchParameter = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		expression.Catch = p.parseBlockStatement()
+	}
+
+	if p.peekTokenIs(token.FINALLY) {
+		p.nextToken()
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		expression.Finally = p.parseBlockStatement()
+	}
+
+	if expression.Catch == nil && expression.Finally == nil {
+		p.addError(&Error{
+			Code:     UNEXPECTED_TOKEN,
+			Message:  fmt.Sprintf("expected catch or finally after try block, got %s instead", p.peekToken.Type),
+			Position: p.peekToken.Start,
+			Expected: []token.TokenType{token.CATCH, token.FINALLY},
+			Found:    p.peekToken,
+		})
+		return nil
+	}
+
+	return expression
+}
+
+func (p *Parser) parseThrowExpression() ast.Expression {
+	expression := &ast.ThrowExpression{Token: p.currentToken}
+
+	p.nextToken()
+
+	expression.Value = p.parseExpression(LOWEST)
+	if expression.Value == nil {
+		return nil
+	}
+
+	return expression
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	blockStatement := &ast.BlockStatement{
 		Token:      p.currentToken,
 		Statements: []ast.Statement{},
 	}
 
+	depth := p.depth
 	p.nextToken()
 
 	for !p.currentTokenIs(token.RBRACE) && !p.currentTokenIs(token.EOF) {
 		statement := p.parseStatement()
+		if p.recoverStatement(depth) {
+			if p.depth < depth {
+				break
+			}
+
+			statement = nil
+		}
+
 		if statement != nil {
 			blockStatement.Statements = append(blockStatement.Statements, statement)
 		}
 		p.nextToken()
 	}
 
+	blockStatement.EndToken = p.currentToken
+
 	return blockStatement
 }
 
@@ -338,7 +684,7 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		return nil
 	}
 
-	literal.Parameters = p.parseFunctionParameters()
+	literal.Parameters, literal.Defaults, literal.Rest = p.parseFunctionParameters()
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -349,41 +695,114 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return literal
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
+func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, []ast.Expression, *ast.Identifier) {
 	params := []*ast.Identifier{}
+	var defaults []ast.Expression
+	var rest *ast.Identifier
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return params
+		return params, defaults, rest
 	}
 
-	p.nextToken()
+	for {
+		p.nextToken()
 
-	identifier := &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
-	params = append(params, identifier)
+		if p.currentTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return nil, nil, nil
+			}
+
+			rest = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+			if p.peekTokenIs(token.COMMA) {
+				p.addError(&Error{
+					Code:     INVALID_PARAMETER,
+					Message:  "rest parameter must be the last parameter",
+					Position: p.peekToken.Start,
+					Expected: []token.TokenType{token.RPAREN},
+					Found:    p.peekToken,
+				})
+				return nil, nil, nil
+			}
+
+			break
+		}
 
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
-		p.nextToken()
+		if !p.currentTokenIs(token.IDENT) {
+			p.addError(&Error{
+				Code:     INVALID_PARAMETER,
+				Message:  fmt.Sprintf("expected parameter name, got %s instead", p.currentToken.Type),
+				Position: p.currentToken.Start,
+				Expected: []token.TokenType{token.IDENT},
+				Found:    p.currentToken,
+			})
+			return nil, nil, nil
+		}
 
 		identifier := &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+		var def ast.Expression
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+
+			def = p.parseExpression(LOWEST)
+			if def == nil {
+				return nil, nil, nil
+			}
+		} else if defaults != nil {
+			p.errorAt(INVALID_PARAMETER, identifier.Pos(), fmt.Sprintf("parameter %s without a default follows a parameter with a default", identifier.Value))
+			return nil, nil, nil
+		}
+
+		if def != nil && defaults == nil {
+			defaults = make([]ast.Expression, len(params))
+		}
+
 		params = append(params, identifier)
+		if defaults != nil {
+			defaults = append(defaults, def)
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+
+		p.nextToken()
 	}
 
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		return nil, nil, nil
 	}
 
-	return params
+	return params, defaults, rest
 }
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	expression := &ast.CallExpression{Token: p.currentToken, Function: function}
-	expression.Arguments = p.parseExpressionList(token.RPAREN)
+	expression.Arguments = p.parseExpressionList(token.RPAREN, p.parseCallArgument)
+	expression.EndToken = p.currentToken
 
 	return expression
 }
 
+func (p *Parser) parseCallArgument() ast.Expression {
+	if !p.currentTokenIs(token.ELLIPSIS) {
+		return p.parseExpression(LOWEST)
+	}
+
+	spread := &ast.SpreadExpression{Token: p.currentToken}
+
+	p.nextToken()
+	spread.Value = p.parseExpression(LOWEST)
+	if spread.Value == nil {
+		return nil
+	}
+
+	return spread
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.currentToken, Value: p.currentToken.Literal}
 }
@@ -391,12 +810,13 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.currentToken}
 
-	array.Elements = p.parseExpressionList(token.RBRACKET)
+	array.Elements = p.parseExpressionList(token.RBRACKET, p.parseListElement)
+	array.EndToken = p.currentToken
 
 	return array
 }
 
-func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+func (p *Parser) parseExpressionList(end token.TokenType, parseElement func() ast.Expression) []ast.Expression {
 	list := []ast.Expression{}
 
 	if p.peekTokenIs(end) {
@@ -405,12 +825,12 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	}
 
 	p.nextToken()
-	list = append(list, p.parseExpression(LOWEST))
+	list = append(list, parseElement())
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		list = append(list, p.parseExpression(LOWEST))
+		list = append(list, parseElement())
 	}
 
 	if !p.expectPeek(end) {
@@ -420,6 +840,10 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	return list
 }
 
+func (p *Parser) parseListElement() ast.Expression {
+	return p.parseExpression(LOWEST)
+}
+
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	exp := &ast.IndexExpression{Token: p.currentToken, Left: left}
 
@@ -430,6 +854,8 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 		return nil
 	}
 
+	exp.EndToken = p.currentToken
+
 	return exp
 }
 
@@ -459,6 +885,8 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		return nil
 	}
 
+	hash.EndToken = p.currentToken
+
 	return hash
 }
 
@@ -469,7 +897,13 @@ func (p *Parser) parseMacroLiteral() ast.Expression {
 		return nil
 	}
 
-	lit.Parameters = p.parseFunctionParameters()
+	var defaults []ast.Expression
+
+	lit.Parameters, defaults, lit.Rest = p.parseFunctionParameters()
+	if defaults != nil {
+		p.errorAt(INVALID_PARAMETER, lit.Pos(), "macro parameters cannot have default values")
+		return nil
+	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -480,7 +914,7 @@ func (p *Parser) parseMacroLiteral() ast.Expression {
 	return lit
 }
 
-func (p *Parser)  parseNull() ast.Expression {
+func (p *Parser) parseNull() ast.Expression {
 	return &ast.Null{Token: p.currentToken}
 }
 
@@ -2,10 +2,12 @@ package parser
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"monkeylang/ast"
 	"monkeylang/lexer"
+	"monkeylang/token"
 )
 
 func TestLetStatements(t *testing.T) {
@@ -197,6 +199,43 @@ func TestIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14;", 3.14},
+		{".5;", 0.5},
+		{"1e-9;", 1e-9},
+		{"2.5E3;", 2500},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements expected to have 1 statement, got %d", len(program.Statements))
+		}
+
+		statement, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("statement: not *ast.ExpressionStatement, got %T", program.Statements[0])
+		}
+
+		literal, ok := statement.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("literal not *ast.FloatLiteral. got=%T", statement.Expression)
+		}
+
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %g. got=%g", tt.expected, literal.Value)
+		}
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input    string
@@ -284,6 +323,11 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"5 - 5;", 5, "-", 5},
 		{"5 * 5;", 5, "*", 5},
 		{"5 / 5;", 5, "/", 5},
+		{"5 % 5;", 5, "%", 5},
+		{"5 <= 5;", 5, "<=", 5},
+		{"5 >= 5;", 5, ">=", 5},
+		{"true && false", true, "&&", false},
+		{"false || true", false, "||", true},
 		{"5 > 5;", 5, ">", 5},
 		{"5 < 5;", 5, "<", 5},
 		{"5 == 5;", 5, "==", 5},
@@ -435,6 +479,30 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"a + b % c * d",
+			"(a + ((b % c) * d))",
+		},
+		{
+			"a || b && c == d",
+			"(a || (b && (c == d)))",
+		},
+		{
+			"a <= b && c >= d || !e",
+			"(((a <= b) && (c >= d)) || (!e))",
+		},
+		{
+			"x = a || b",
+			"(x = (a || b))",
+		},
+		{
+			"a = b = c + 1",
+			"(a = (b = (c + 1)))",
+		},
+		{
+			"a[i + 1] += b == c",
+			"((a[(i + 1)]) += (b == c))",
+		},
 	}
 
 	for _, tt := range tests {
@@ -612,6 +680,264 @@ func TestIfElseExpression(t *testing.T) {
 	}
 }
 
+func TestWhileExpression(t *testing.T) {
+	input := `while (x < 10) { x; break; continue }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.WhileExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.WhileExpression. got=%T", stmt.Expression)
+	}
+
+	if !testInfixExpression(t, exp.Condition, "x", "<", 10) {
+		return
+	}
+
+	if len(exp.Body.Statements) != 3 {
+		t.Fatalf("body is not 3 statements. got=%d", len(exp.Body.Statements))
+	}
+
+	if _, ok := exp.Body.Statements[1].(*ast.BreakStatement); !ok {
+		t.Errorf("body.Statements[1] is not ast.BreakStatement. got=%T", exp.Body.Statements[1])
+	}
+
+	if _, ok := exp.Body.Statements[2].(*ast.ContinueStatement); !ok {
+		t.Errorf("body.Statements[2] is not ast.ContinueStatement. got=%T", exp.Body.Statements[2])
+	}
+}
+
+func TestForExpression(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedKey        string
+		expectedValue      string
+		expectedCollection string
+		expectedString     string
+	}{
+		{"for (x in xs) { x }", "", "x", "xs", "for (x in xs) x"},
+		{"for (i, x in [1, 2]) { i }", "i", "x", "[1, 2]", "for (i, x in [1, 2]) i"},
+		{`for (k, v in {"a": 1}) { v }`, "k", "v", "{a: 1}", "for (k, v in {a: 1}) v"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		exp, ok := stmt.Expression.(*ast.ForExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.ForExpression. got=%T", stmt.Expression)
+		}
+
+		if tt.expectedKey == "" {
+			if exp.Key != nil {
+				t.Errorf("expected no key variable, got=%s", exp.Key)
+			}
+		} else if !testIdentifier(t, exp.Key, tt.expectedKey) {
+			return
+		}
+
+		if !testIdentifier(t, exp.Value, tt.expectedValue) {
+			return
+		}
+
+		if exp.Collection.String() != tt.expectedCollection {
+			t.Errorf("exp.Collection.String() wrong. want=%q, got=%q", tt.expectedCollection, exp.Collection.String())
+		}
+
+		if exp.String() != tt.expectedString {
+			t.Errorf("exp.String() wrong. want=%q, got=%q", tt.expectedString, exp.String())
+		}
+	}
+}
+
+func TestTryExpression(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedCatch    string
+		expectedHasFinal bool
+		expectedString   string
+	}{
+		{"try { x } catch (e) { y }", "e", false, "try x catch (e) y"},
+		{"try { x } finally { z }", "", true, "try x finally z"},
+		{"try { x } catch (err) { y } finally { z }", "err", true, "try x catch (err) y finally z"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		exp, ok := stmt.Expression.(*ast.TryExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.TryExpression. got=%T", stmt.Expression)
+		}
+
+		if tt.expectedCatch == "" {
+			if exp.Catch != nil || exp.CatchParameter != nil {
+				t.Errorf("expected no catch clause, got=%s", exp.Catch)
+			}
+		} else if !testIdentifier(t, exp.CatchParameter, tt.expectedCatch) {
+			return
+		}
+
+		if (exp.Finally != nil) != tt.expectedHasFinal {
+			t.Errorf("finally clause presence wrong. want=%t, got=%t", tt.expectedHasFinal, exp.Finally != nil)
+		}
+
+		if exp.String() != tt.expectedString {
+			t.Errorf("exp.String() wrong. want=%q, got=%q", tt.expectedString, exp.String())
+		}
+	}
+}
+
+func TestThrowExpression(t *testing.T) {
+	input := `throw "bad" + x;`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.ThrowExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.ThrowExpression. got=%T", stmt.Expression)
+	}
+
+	if exp.Value.String() != "(bad + x)" {
+		t.Errorf("exp.Value.String() wrong. got=%q", exp.Value.String())
+	}
+}
+
+func TestAssignExpression(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedTarget string
+		operator       string
+		expectedValue  string
+	}{
+		{"x = 5;", "x", "=", "5"},
+		{"x += y * 2;", "x", "+=", "(y * 2)"},
+		{"x -= 1", "x", "-=", "1"},
+		{"x *= 2", "x", "*=", "2"},
+		{"x /= 2", "x", "/=", "2"},
+		{"x %= 2", "x", "%=", "2"},
+		{"xs[0] = 1", "(xs[0])", "=", "1"},
+		{`h["a"]["b"] -= 1`, "((h[a])[b])", "-=", "1"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		exp, ok := stmt.Expression.(*ast.AssignExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.AssignExpression. got=%T", stmt.Expression)
+		}
+
+		if exp.Target.String() != tt.expectedTarget {
+			t.Errorf("exp.Target wrong. want=%q, got=%q", tt.expectedTarget, exp.Target.String())
+		}
+
+		if exp.Operator != tt.operator {
+			t.Errorf("exp.Operator wrong. want=%q, got=%q", tt.operator, exp.Operator)
+		}
+
+		if exp.Value.String() != tt.expectedValue {
+			t.Errorf("exp.Value wrong. want=%q, got=%q", tt.expectedValue, exp.Value.String())
+		}
+	}
+}
+
+func TestAssignExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5 = x", "1:1: cannot assign to 5"},
+		{"a + b = c", "1:1: cannot assign to (a + b)"},
+		{"f() += 1", "1:1: cannot assign to f()"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0].Error() != tt.expected {
+			t.Errorf("wrong parser errors for %q. want first=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+func TestTryExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { x }", "1:10: expected catch or finally after try block, got EOF instead"},
+		{"try { x } catch { y }", "1:17: expected next token to be (, got { instead"},
+		{"try { x } catch (1) { y }", "1:18: expected next token to be IDENT, got INT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0].Error() != tt.expected {
+			t.Errorf("wrong parser errors for %q. want first=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
 func TestFunctionLiteralParsing(t *testing.T) {
 	input := `fn(x, y) { x + y; }`
 
@@ -688,6 +1014,113 @@ func TestFunctionParameterParsing(t *testing.T) {
 	}
 }
 
+func TestDefaultAndRestParameterParsing(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedParams   []string
+		expectedDefaults []string
+		expectedRest     string
+	}{
+		{"fn(a, b = 2) {}", []string{"a", "b"}, []string{"", "2"}, ""},
+		{"fn(a = 1, b = a * 2) {}", []string{"a", "b"}, []string{"1", "(a * 2)"}, ""},
+		{"fn(...rest) {}", []string{}, nil, "rest"},
+		{"fn(a, b = [], ...rest) {}", []string{"a", "b"}, []string{"", "[]"}, "rest"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+
+		if len(function.Parameters) != len(tt.expectedParams) {
+			t.Fatalf("length parameters wrong for %q. want %d, got=%d", tt.input, len(tt.expectedParams), len(function.Parameters))
+		}
+
+		for i, ident := range tt.expectedParams {
+			testLiteralExpression(t, function.Parameters[i], ident)
+
+			def := function.Default(i)
+			if tt.expectedDefaults[i] == "" {
+				if def != nil {
+					t.Errorf("parameter %s of %q should have no default. got=%s", ident, tt.input, def)
+				}
+				continue
+			}
+
+			if def == nil || def.String() != tt.expectedDefaults[i] {
+				t.Errorf("wrong default for parameter %s of %q. want=%s, got=%v", ident, tt.input, tt.expectedDefaults[i], def)
+			}
+		}
+
+		if tt.expectedRest == "" {
+			if function.Rest != nil {
+				t.Errorf("%q should have no rest parameter. got=%s", tt.input, function.Rest)
+			}
+		} else if function.Rest == nil || function.Rest.Value != tt.expectedRest {
+			t.Errorf("wrong rest parameter for %q. want=%s, got=%v", tt.input, tt.expectedRest, function.Rest)
+		}
+	}
+}
+
+func TestFunctionParameterErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(1) {}", "1:4: expected parameter name, got INT instead"},
+		{"fn(a = 1, b) {}", "1:11: parameter b without a default follows a parameter with a default"},
+		{"fn(...rest, a) {}", "1:11: rest parameter must be the last parameter"},
+		{"fn(...) {}", "1:7: expected next token to be IDENT, got ) instead"},
+		{"macro(a = 1) { a }", "1:1: macro parameters cannot have default values"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0].Error() != tt.expected {
+			t.Errorf("wrong parser errors for %q. want first=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+func TestCallExpressionSpreadParsing(t *testing.T) {
+	input := "add(1, ...rest, ...[2, 3])"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T", stmt.Expression)
+	}
+
+	if len(exp.Arguments) != 3 {
+		t.Fatalf("wrong length of arguments. got=%d", len(exp.Arguments))
+	}
+
+	testIntegerLiteral(t, exp.Arguments[0], 1)
+
+	spread, ok := exp.Arguments[1].(*ast.SpreadExpression)
+	if !ok {
+		t.Fatalf("argument 1 is not ast.SpreadExpression. got=%T", exp.Arguments[1])
+	}
+	testIdentifier(t, spread.Value, "rest")
+
+	if exp.String() != "add(1, ...rest, ...[2, 3])" {
+		t.Errorf("exp.String() wrong. got=%q", exp.String())
+	}
+}
+
 func TestCallExpressionParsing(t *testing.T) {
 	input := "add(1, 2 * 3, 4 + 5);"
 
@@ -1087,3 +1520,264 @@ func TestMacroLiteralParsing(t *testing.T) {
 
 	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
 }
+
+func TestVariadicMacroLiteralParsing(t *testing.T) {
+	input := `macro(first, ...rest) { first }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	macro, ok := stmt.Expression.(*ast.MacroLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MacroLiteral. got=%T\n", stmt.Expression)
+	}
+
+	if len(macro.Parameters) != 1 {
+		t.Fatalf("macro literal got invalid number of parameters. expected=%d, got=%d\n", 1, len(macro.Parameters))
+	}
+
+	testLiteralExpression(t, macro.Parameters[0], "first")
+
+	if macro.Rest == nil {
+		t.Fatalf("macro.Rest is nil")
+	}
+
+	testLiteralExpression(t, macro.Rest, "rest")
+}
+
+func TestUnquoteBindingParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let unquote(x) = 1;", "let unquote(x) = 1;"},
+		{"unquote(a) = unquote(b)", "(unquote(a) = unquote(b))"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	input := `let add = fn(x, y) {
+  x + y
+};
+add(1, [2, 3][0]);`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements doesn't contain %d statements. got=%d\n", 2, len(program.Statements))
+	}
+
+	letStmt := program.Statements[0].(*ast.LetStatement)
+	function := letStmt.Value.(*ast.FunctionLiteral)
+	body := function.Body.Statements[0].(*ast.ExpressionStatement)
+	call := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	index := call.Arguments[1]
+
+	tests := []struct {
+		node          ast.Node
+		expectedStart string
+		expectedEnd   string
+	}{
+		{letStmt, "1:1", "3:2"},
+		{function, "1:11", "3:2"},
+		{body, "2:3", "2:8"},
+		{call, "4:1", "4:18"},
+		{index, "4:8", "4:17"},
+		{program, "1:1", "4:18"},
+	}
+
+	for _, tt := range tests {
+		if tt.node.Pos().String() != tt.expectedStart {
+			t.Errorf("wrong start for %q. expected=%s, got=%s", tt.node.String(), tt.expectedStart, tt.node.Pos())
+		}
+
+		if tt.node.End().String() != tt.expectedEnd {
+			t.Errorf("wrong end for %q. expected=%s, got=%s", tt.node.String(), tt.expectedEnd, tt.node.End())
+		}
+	}
+}
+
+func TestParserErrorPositions(t *testing.T) {
+	input := `let x = 5;
+let = 10;`
+
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+
+	expected := "2:5: expected next token to be IDENT, got = instead"
+	if errors[0].Error() != expected {
+		t.Errorf("wrong error. expected=%q, got=%q", expected, errors[0].Error())
+	}
+}
+
+func TestParserErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedErrors     []string
+		expectedStatements int
+	}{
+		{
+			"let = 10;\nlet x = 5;\nlet y = ;",
+			[]string{
+				"1:5: expected next token to be IDENT, got = instead",
+				"3:9: no prefix parse function for ; found",
+			},
+			1,
+		},
+		{
+			"let x 5\nlet y = 3;",
+			[]string{"1:7: expected next token to be =, got INT instead"},
+			1,
+		},
+		{
+			"fn() {\n  let = 1;\n  x +\n}\nlet z = 2;",
+			[]string{
+				"2:7: expected next token to be IDENT, got = instead",
+				"4:1: no prefix parse function for } found",
+			},
+			2,
+		},
+		{
+			"if (x { 1 }\nlet a = 1;",
+			[]string{"1:7: expected next token to be ), got { instead"},
+			1,
+		},
+		{
+			"}\nlet a = 1;",
+			[]string{"1:1: no prefix parse function for } found"},
+			1,
+		},
+		{
+			"let a = 1 & 2;\nlet b = 3;",
+			[]string{"1:11: no prefix parse function for ILLEGAL found"},
+			2,
+		},
+		{
+			"let a = 1..2;\nlet b = 3;",
+			[]string{"1:9: malformed number literal 1..2"},
+			1,
+		},
+		{
+			"let f = fn(x) {\n  if (x) { [1, 2 } else { 3 }\n};\nf(1);",
+			[]string{"2:18: expected next token to be ], got } instead"},
+			2,
+		},
+		{
+			`{"a": }`,
+			[]string{"1:7: no prefix parse function for } found"},
+			0,
+		},
+		{
+			"fn(a, b = 2, c) {}\nlet d = 4;",
+			[]string{"1:14: parameter c without a default follows a parameter with a default"},
+			1,
+		},
+		{
+			"}}}\nlet a = 1;",
+			[]string{"1:1: no prefix parse function for } found"},
+			1,
+		},
+		{
+			"let x = ;\n}}}\nlet y = 3 +;",
+			[]string{
+				"1:9: no prefix parse function for ; found",
+				"2:1: no prefix parse function for } found",
+				"3:12: no prefix parse function for ; found",
+			},
+			0,
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != len(tt.expectedErrors) {
+			t.Errorf("wrong number of errors for %q. want=%d, got=%d (%q)", tt.input, len(tt.expectedErrors), len(errors), errors)
+			continue
+		}
+
+		for i, expected := range tt.expectedErrors {
+			if errors[i].Error() != expected {
+				t.Errorf("wrong error %d for %q. want=%q, got=%q", i, tt.input, expected, errors[i].Error())
+			}
+		}
+
+		if len(program.Statements) != tt.expectedStatements {
+			t.Errorf("wrong number of statements for %q. want=%d, got=%d", tt.input, tt.expectedStatements, len(program.Statements))
+		}
+	}
+}
+
+func TestStructuredParserErrors(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedCode     ErrorCode
+		expectedPosition string
+		expectedExpected []token.TokenType
+		expectedFound    token.TokenType
+	}{
+		{"let = 10;", UNEXPECTED_TOKEN, "1:5", []token.TokenType{token.IDENT}, token.ASSIGN},
+		{"try { x }", UNEXPECTED_TOKEN, "1:10", []token.TokenType{token.CATCH, token.FINALLY}, token.EOF},
+		{"1 + ;", MISSING_EXPRESSION, "1:5", nil, token.SEMICOLON},
+		{"#", ILLEGAL_TOKEN, "1:1", nil, token.ILLEGAL},
+		{"99999999999999999999", INVALID_NUMBER, "1:1", nil, token.INT},
+		{"1e5e3", INVALID_NUMBER, "1:1", nil, token.ILLEGAL},
+		{"1 = 2", INVALID_ASSIGNMENT, "1:1", nil, token.ASSIGN},
+		{"fn(a = 1, b) {}", INVALID_PARAMETER, "1:11", nil, token.IDENT},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q, got none", tt.input)
+			continue
+		}
+
+		err := errors[0]
+		if err.Code != tt.expectedCode {
+			t.Errorf("wrong code for %q. want=%s, got=%s", tt.input, tt.expectedCode, err.Code)
+		}
+
+		if err.Position.String() != tt.expectedPosition {
+			t.Errorf("wrong position for %q. want=%s, got=%s", tt.input, tt.expectedPosition, err.Position)
+		}
+
+		if !reflect.DeepEqual(err.Expected, tt.expectedExpected) {
+			t.Errorf("wrong expected tokens for %q. want=%v, got=%v", tt.input, tt.expectedExpected, err.Expected)
+		}
+
+		if err.Found.Type != tt.expectedFound {
+			t.Errorf("wrong found token for %q. want=%s, got=%s", tt.input, tt.expectedFound, err.Found.Type)
+		}
+	}
+}
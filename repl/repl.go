@@ -5,36 +5,100 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
+	"monkeylang/engine"
 	"monkeylang/evaluator"
 	"monkeylang/lexer"
 	"monkeylang/object"
 	"monkeylang/parser"
+	"monkeylang/token"
 )
 
-const PROMPT = "$> "
+const (
+	PROMPT              = "$> "
+	CONTINUATION_PROMPT = ".. "
+)
+
+var continuationTokens = map[token.TokenType]bool{
+	token.ASSIGN:   true,
+	token.PLUS:     true,
+	token.MINUS:    true,
+	token.SLASH:    true,
+	token.ASTERISK: true,
+	token.PERCENT:  true,
 
-func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
-	macroEnv := object.NewEnvironment()
+	token.PLUS_ASSIGN:     true,
+	token.MINUS_ASSIGN:    true,
+	token.ASTERISK_ASSIGN: true,
+	token.SLASH_ASSIGN:    true,
+	token.PERCENT_ASSIGN:  true,
+
+	token.LT:       true,
+	token.GT:       true,
+	token.LT_EQ:    true,
+	token.GT_EQ:    true,
+	token.AND:      true,
+	token.OR:       true,
+	token.EQ:       true,
+	token.NOT_EQ:   true,
+	token.BANG:     true,
+	token.COMMA:    true,
+	token.COLON:    true,
+	token.LET:      true,
+	token.RETURN:   true,
+	token.FUNCTION: true,
+	token.MACRO:    true,
+	token.IF:       true,
+	token.ELSE:     true,
+	token.WHILE:    true,
+	token.FOR:      true,
+	token.IN:       true,
+	token.TRY:      true,
+	token.CATCH:    true,
+	token.FINALLY:  true,
+	token.THROW:    true,
+}
+
+func Start(in io.Reader, out io.Writer, backend engine.Backend) {
+	StartEngine(in, out, engine.New(backend))
+}
+
+func StartEngine(in io.Reader, out io.Writer, e *engine.Engine) {
+	reader := bufio.NewReader(in)
+	e.Builtins().SetStdout(out)
+	e.Builtins().SetStderr(out)
+	e.Builtins().SetStdin(reader)
+	lines := []string{}
 
 	for {
-		fmt.Fprintf(out, PROMPT)
-		scanned := scanner.Scan()
+		if len(lines) == 0 {
+			fmt.Fprintf(out, PROMPT)
+		} else {
+			fmt.Fprintf(out, CONTINUATION_PROMPT)
+		}
+
+		line, ok := readLine(reader)
 
-		if !scanned {
+		if !ok {
 			return
 		}
 
-		line := scanner.Text()
-
-		if line == ".exit" {
-			fmt.Println("Bye!")
+		if len(lines) == 0 && line == ".exit" {
+			fmt.Fprintln(out, "Bye!")
 			os.Exit(0)
 		}
 
-		l := lexer.New(line)
+		lines = append(lines, line)
+		input := strings.Join(lines, "\n")
+
+		if isIncomplete(input) && !endsWithBlankLines(lines) {
+			continue
+		}
+
+		lines = []string{}
+
+		l := lexer.New(input)
 		p := parser.New(l)
 		program := p.ParseProgram()
 
@@ -43,11 +107,11 @@ func Start(in io.Reader, out io.Writer) {
 			continue
 		}
 
-		evaluator.DefineMacros(program, macroEnv)
-		expanded := evaluator.ExpandMacros(program, macroEnv)
-
-		evaluated := evaluator.Eval(expanded, env)
-		if evaluated != nil && evaluated != evaluator.NULL {
+		evaluated := e.Execute(program)
+		if errObj, ok := evaluated.(*object.Error); ok {
+			io.WriteString(out, errObj.Traceback())
+			io.WriteString(out, "\n")
+		} else if evaluated != nil && evaluated != evaluator.NULL {
 			io.WriteString(out, evaluated.Inspect())
 			io.WriteString(out, "\n")
 		}
@@ -55,8 +119,51 @@ func Start(in io.Reader, out io.Writer) {
 	}
 }
 
-func printParserErrors(out io.Writer, errors []string) {
-	for _, msg := range errors {
-		io.WriteString(out, "\t"+msg+"\n")
+func readLine(reader *bufio.Reader) (string, bool) {
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+
+	return line, true
+}
+
+func isIncomplete(input string) bool {
+	if strings.Count(input, `"`)%2 == 1 {
+		return true
+	}
+
+	l := lexer.New(input)
+	depth := 0
+
+	var last token.Token
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACE, token.LBRACKET:
+			depth += 1
+		case token.RPAREN, token.RBRACE, token.RBRACKET:
+			depth -= 1
+		}
+
+		last = tok
+	}
+
+	return depth > 0 || continuationTokens[last.Type]
+}
+
+func endsWithBlankLines(lines []string) bool {
+	if len(lines) < 3 {
+		return false
+	}
+
+	return strings.TrimSpace(lines[len(lines)-1]) == "" && strings.TrimSpace(lines[len(lines)-2]) == ""
+}
+
+func printParserErrors(out io.Writer, errors []*parser.Error) {
+	for _, err := range errors {
+		io.WriteString(out, "\t"+err.Error()+"\n")
 	}
 }
@@ -0,0 +1,98 @@
+package repl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"monkeylang/engine"
+)
+
+func TestIsIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"let x = 5;", false},
+		{"5 + 5", false},
+		{"let add = fn(a, b) {", true},
+		{"let add = fn(a, b) {\n  a + b\n};", false},
+		{"[1, 2,", true},
+		{"[1, 2, 3]", false},
+		{"add(1,\n2", true},
+		{"{\"a\": 1", true},
+		{"5 +", true},
+		{"let x =", true},
+		{"x +=", true},
+		{"x %", true},
+		{"a &&", true},
+		{"a >=", true},
+		{"if (x) { 1 } else", true},
+		{"\"hello", true},
+		{"\"hello\nworld\"", false},
+		{"\"{\"", false},
+		{"}", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isIncomplete(tt.input); got != tt.expected {
+			t.Errorf("isIncomplete(%q) wrong. want=%t, got=%t", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestStartMultiLineInput(t *testing.T) {
+	input := strings.Join([]string{
+		"let add = fn(a, b) {",
+		"  a +",
+		"    b",
+		"};",
+		"add(1,",
+		"  2)",
+		"let broken = [1,",
+		"",
+		"",
+		"add(2, 3)",
+	}, "\n")
+
+	for _, backend := range []engine.Backend{engine.EvaluatorBackend, engine.VMBackend} {
+		var out bytes.Buffer
+		Start(strings.NewReader(input), &out, backend)
+
+		output := out.String()
+
+		expected := PROMPT + CONTINUATION_PROMPT + CONTINUATION_PROMPT + CONTINUATION_PROMPT +
+			PROMPT + CONTINUATION_PROMPT + "3\n"
+		if !strings.HasPrefix(output, expected) {
+			t.Errorf("[%s] wrong output prefix. want=%q, got=%q", backend, expected, output)
+		}
+
+		if !strings.Contains(output, "\t") {
+			t.Errorf("[%s] expected parser errors after blank lines, got=%q", backend, output)
+		}
+
+		if !strings.HasSuffix(output, PROMPT+"5\n"+PROMPT) {
+			t.Errorf("[%s] wrong output suffix. got=%q", backend, output)
+		}
+	}
+}
+
+func TestStartRoutesBuiltinIO(t *testing.T) {
+	input := strings.Join([]string{
+		`puts("hi")`,
+		`let name = readline()`,
+		`world`,
+		`eprint("hello", name)`,
+	}, "\n")
+
+	for _, backend := range []engine.Backend{engine.EvaluatorBackend, engine.VMBackend} {
+		var out bytes.Buffer
+		Start(strings.NewReader(input), &out, backend)
+
+		expected := PROMPT + "hi\n" + PROMPT + PROMPT + "hello world\n" + PROMPT
+		if out.String() != expected {
+			t.Errorf("[%s] wrong output. want=%q, got=%q", backend, expected, out.String())
+		}
+	}
+}
@@ -5,6 +5,8 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+	Start   Position
+	End     Position
 }
 
 const (
@@ -13,6 +15,7 @@ const (
 
 	IDENT  = "IDENT"
 	INT    = "INT"
+	FLOAT  = "FLOAT"
 	STRING = "STRING"
 	NULL   = "NULL"
 	MACRO  = "MACRO"
@@ -22,16 +25,29 @@ const (
 	MINUS    = "-"
 	SLASH    = "/"
 	ASTERISK = "*"
+	PERCENT  = "%"
+
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+	PERCENT_ASSIGN  = "%="
 
 	LT     = "<"
 	GT     = ">"
+	LT_EQ  = "<="
+	GT_EQ  = ">="
 	EQ     = "=="
 	NOT_EQ = "!="
 	BANG   = "!"
 
+	AND = "&&"
+	OR  = "||"
+
 	COMMA     = ","
 	COLON     = ":"
 	SEMICOLON = ";"
+	ELLIPSIS  = "..."
 
 	LPAREN   = "("
 	RPAREN   = ")"
@@ -48,6 +64,17 @@ const (
 	ELSE  = "ELSE"
 	TRUE  = "TRUE"
 	FALSE = "FALSE"
+
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	IN       = "IN"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+
+	TRY     = "TRY"
+	CATCH   = "CATCH"
+	FINALLY = "FINALLY"
+	THROW   = "THROW"
 )
 
 var keywords = map[string]TokenType{
@@ -61,6 +88,17 @@ var keywords = map[string]TokenType{
 	"false": FALSE,
 	"null":  NULL,
 	"macro": MACRO,
+
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+
+	"try":     TRY,
+	"catch":   CATCH,
+	"finally": FINALLY,
+	"throw":   THROW,
 }
 
 func LookupIdentifier(identifier string) TokenType {
@@ -0,0 +1,40 @@
+package vm
+
+import (
+	"monkeylang/code"
+	"monkeylang/object"
+	"monkeylang/token"
+)
+
+type Frame struct {
+	cl          *object.Closure
+	ip          int
+	locals      []object.Object
+	basePointer int
+	numArgs     int
+	callSite    token.Position
+	caller      string
+	tailCalls   int
+}
+
+func NewFrame(cl *object.Closure, locals []object.Object, basePointer int) *Frame {
+	return &Frame{
+		cl:          cl,
+		ip:          -1,
+		locals:      locals,
+		basePointer: basePointer,
+	}
+}
+
+func (f *Frame) Instructions() code.Instructions {
+	return f.cl.Fn.Instructions
+}
+
+func (f *Frame) Position(ip int) token.Position {
+	return f.cl.Fn.Positions[ip]
+}
+
+func (f *Frame) CallPosition() token.Position {
+	def, _ := code.Lookup(byte(code.OpCall))
+	return f.Position(f.ip - def.OperandWidths[0])
+}
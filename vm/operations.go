@@ -0,0 +1,273 @@
+package vm
+
+import (
+	"math"
+
+	"monkeylang/code"
+	"monkeylang/object"
+)
+
+var infixOperators = map[code.Opcode]string{
+	code.OpAdd:          "+",
+	code.OpSub:          "-",
+	code.OpMul:          "*",
+	code.OpDiv:          "/",
+	code.OpMod:          "%",
+	code.OpEqual:        "==",
+	code.OpNotEqual:     "!=",
+	code.OpGreaterThan:  ">",
+	code.OpLessThan:     "<",
+	code.OpGreaterEqual: ">=",
+	code.OpLessEqual:    "<=",
+}
+
+func executeInfixOperation(operator string, left, right object.Object) object.Object {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return executeIntegerInfixOperation(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return executeFloatInfixOperation(operator, left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return executeStringInfixOperation(operator, left, right)
+	case operator == "==":
+		return nativeBoolToBoolean(left == right)
+	case operator == "!=":
+		return nativeBoolToBoolean(left != right)
+	case left.Type() != right.Type():
+		return newError(object.TYPE_ERROR, "type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	default:
+		return newError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func executeIntegerInfixOperation(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Integer).Value
+	rightVal := right.(*object.Integer).Value
+
+	switch operator {
+	case "+":
+		return &object.Integer{Value: leftVal + rightVal}
+	case "-":
+		return &object.Integer{Value: leftVal - rightVal}
+	case "*":
+		return &object.Integer{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError(object.ZERO_DIVISION_ERROR, "division by zero")
+		}
+		return &object.Integer{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError(object.ZERO_DIVISION_ERROR, "modulo by zero")
+		}
+		return &object.Integer{Value: leftVal % rightVal}
+	case "<":
+		return nativeBoolToBoolean(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBoolean(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBoolean(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBoolean(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBoolean(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBoolean(leftVal != rightVal)
+	default:
+		return newError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func executeFloatInfixOperation(operator string, left, right object.Object) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "<":
+		return nativeBoolToBoolean(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBoolean(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBoolean(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBoolean(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBoolean(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBoolean(leftVal != rightVal)
+	default:
+		return newError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	default:
+		return 0
+	}
+}
+
+func executeStringInfixOperation(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.String).Value
+	rightVal := right.(*object.String).Value
+
+	switch operator {
+	case "+":
+		return &object.String{Value: leftVal + rightVal}
+	case "<":
+		return nativeBoolToBoolean(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBoolean(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBoolean(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBoolean(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBoolean(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBoolean(leftVal != rightVal)
+	default:
+		return newError(object.TYPE_ERROR, "unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func executeBangOperator(operand object.Object) object.Object {
+	switch operand {
+	case TRUE:
+		return FALSE
+	case FALSE:
+		return TRUE
+	default:
+		return FALSE
+	}
+}
+
+func executeMinusOperator(operand object.Object) object.Object {
+	switch operand := operand.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: -operand.Value}
+	case *object.Float:
+		return &object.Float{Value: -operand.Value}
+	default:
+		return newError(object.TYPE_ERROR, "unknown operator: -%s", operand.Type())
+	}
+}
+
+func executeIndexExpression(left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return executeArrayIndex(left, index)
+	case left.Type() == object.HASH_OBJ:
+		return executeHashIndex(left, index)
+	case left.Type() == object.ERROR_VALUE_OBJ:
+		return executeErrorValueIndex(left, index)
+	default:
+		return newError(object.TYPE_ERROR, "index operator not supported: %s", left.Type())
+	}
+}
+
+func executeSetIndex(left, index, value object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		i, ok := index.(*object.Integer)
+		if !ok {
+			return newError(object.TYPE_ERROR, "array index must be INTEGER, got %s", index.Type())
+		}
+
+		if i.Value < 0 || i.Value >= int64(len(left.Elements)) {
+			return newError(object.INDEX_ERROR, "index out of range: %d", i.Value)
+		}
+
+		left.Elements[i.Value] = value
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError(object.TYPE_ERROR, "unusable as hash key: %s", index.Type())
+		}
+
+		left.Pairs[key.HashKey()] = object.HashPair{Key: index, Value: value}
+	default:
+		return newError(object.TYPE_ERROR, "index assignment not supported: %s", left.Type())
+	}
+
+	return value
+}
+
+func executeArrayIndex(array, index object.Object) object.Object {
+	arrayObject := array.(*object.Array)
+	i := index.(*object.Integer).Value
+	max := int64(len(arrayObject.Elements) - 1)
+
+	if i < 0 || i > max {
+		return NULL
+	}
+
+	return arrayObject.Elements[i]
+}
+
+func executeHashIndex(hash, index object.Object) object.Object {
+	hashObject := hash.(*object.Hash)
+
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError(object.TYPE_ERROR, "unusable as hash key: %s", index.Type())
+	}
+
+	pair, ok := hashObject.Pairs[key.HashKey()]
+	if !ok {
+		return NULL
+	}
+
+	return pair.Value
+}
+
+func executeErrorValueIndex(errorValue, index object.Object) object.Object {
+	name, ok := index.(*object.String)
+	if !ok {
+		return newError(object.TYPE_ERROR, "error fields must be STRING, got %s", index.Type())
+	}
+
+	field, ok := errorValue.(*object.ErrorValue).Field(name.Value)
+	if !ok {
+		return NULL
+	}
+
+	return field
+}
+
+func isTruthy(obj object.Object) bool {
+	switch obj {
+	case NULL:
+		return false
+	case FALSE:
+		return false
+	default:
+		return true
+	}
+}
+
+func nativeBoolToBoolean(b bool) *object.Boolean {
+	if b {
+		return TRUE
+	}
+
+	return FALSE
+}
@@ -0,0 +1,35 @@
+package vm
+
+import "monkeylang/object"
+
+const SPREAD_OBJ = "SPREAD"
+
+type spread struct {
+	elements []object.Object
+}
+
+func (s *spread) Type() object.ObjectType { return SPREAD_OBJ }
+func (s *spread) Inspect() string         { return "spread" }
+
+func (vm *VM) expandSpreadArguments(numArgs int) (int, *object.Error) {
+	start := vm.sp - numArgs
+
+	args := make([]object.Object, 0, numArgs)
+	for i := start; i < vm.sp; i++ {
+		if s, ok := vm.stack[i].(*spread); ok {
+			args = append(args, s.elements...)
+		} else {
+			args = append(args, vm.stack[i])
+		}
+		vm.stack[i] = nil
+	}
+	vm.sp = start
+
+	for _, arg := range args {
+		if err := vm.push(arg); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(args), nil
+}
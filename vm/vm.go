@@ -0,0 +1,696 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+
+	"monkeylang/code"
+	"monkeylang/compiler"
+	"monkeylang/object"
+)
+
+const (
+	StackSize    = 2048
+	MaxStackSize = 1 << 20
+	GlobalsSize  = 65536
+)
+
+var (
+	TRUE  = object.TRUE
+	FALSE = object.FALSE
+	NULL  = object.NULL
+)
+
+type VM struct {
+	constants []object.Object
+
+	globals     []object.Object
+	globalNames []string
+
+	builtins *object.BuiltinRegistry
+	limiter  *object.Limiter
+
+	maxRecursionDepth int
+
+	stack []object.Object
+	sp    int
+
+	frames      []*Frame
+	framesIndex int
+
+	handlers []handler
+
+	lastPopped object.Object
+}
+
+type handler struct {
+	framesIndex int
+	sp          int
+	catchIP     int
+}
+
+func New(bytecode *compiler.Bytecode) *VM {
+	mainFn := &object.CompiledFunction{
+		Instructions: bytecode.Instructions,
+		Positions:    bytecode.Positions,
+	}
+	mainClosure := &object.Closure{Fn: mainFn}
+	mainFrame := NewFrame(mainClosure, nil, 0)
+
+	builtins := bytecode.Builtins
+	if builtins == nil {
+		builtins = object.NewBuiltinRegistry()
+	}
+
+	return &VM{
+		constants: bytecode.Constants,
+
+		globals:     make([]object.Object, GlobalsSize),
+		globalNames: bytecode.GlobalNames,
+
+		builtins: builtins,
+
+		maxRecursionDepth: object.DEFAULT_MAX_RECURSION_DEPTH,
+
+		stack: make([]object.Object, StackSize),
+		sp:    0,
+
+		frames:      []*Frame{mainFrame},
+		framesIndex: 1,
+	}
+}
+
+func NewWithGlobalsStore(bytecode *compiler.Bytecode, globals []object.Object) *VM {
+	vm := New(bytecode)
+	vm.globals = globals
+	return vm
+}
+
+func (vm *VM) SetMaxRecursionDepth(depth int) {
+	vm.maxRecursionDepth = depth
+}
+
+func (vm *VM) Run() object.Object {
+	return vm.RunContext(context.Background(), object.Limits{})
+}
+
+func (vm *VM) RunContext(ctx context.Context, limits object.Limits) object.Object {
+	vm.limiter = object.NewLimiter(ctx, limits)
+	return vm.run()
+}
+
+func (vm *VM) run() object.Object {
+	for vm.framesIndex > 0 && vm.currentFrame().ip < len(vm.currentFrame().Instructions())-1 {
+		frame := vm.currentFrame()
+		frame.ip++
+
+		ip := frame.ip
+		ins := frame.Instructions()
+		op := code.Opcode(ins[ip])
+
+		err := vm.limiter.Step()
+		if err != nil {
+			err.Position = frame.Position(ip)
+			vm.unwindFrames(err, 1)
+			return err
+		}
+
+		switch op {
+		case code.OpConstant:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+
+			err = vm.push(vm.constants[constIndex])
+		case code.OpPop:
+			vm.lastPopped = vm.pop()
+		case code.OpDup:
+			count := int(code.ReadUint8(ins[ip+1:]))
+			frame.ip += 1
+
+			start := vm.sp - count
+			for i := 0; i < count && err == nil; i++ {
+				err = vm.push(vm.stack[start+i])
+			}
+		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv, code.OpMod,
+			code.OpEqual, code.OpNotEqual, code.OpGreaterThan, code.OpLessThan,
+			code.OpGreaterEqual, code.OpLessEqual:
+			right := vm.pop()
+			left := vm.pop()
+
+			err = vm.pushResult(vm.allocate(executeInfixOperation(infixOperators[op], left, right)))
+		case code.OpTrue:
+			err = vm.push(TRUE)
+		case code.OpFalse:
+			err = vm.push(FALSE)
+		case code.OpNull:
+			err = vm.push(NULL)
+		case code.OpBang:
+			err = vm.push(executeBangOperator(vm.pop()))
+		case code.OpMinus:
+			err = vm.pushResult(executeMinusOperator(vm.pop()))
+		case code.OpJump:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			frame.ip = pos - 1
+		case code.OpJumpIfArg:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			paramIndex := int(code.ReadUint16(ins[ip+3:]))
+			frame.ip += 4
+
+			if frame.numArgs > paramIndex {
+				frame.ip = pos - 1
+			}
+		case code.OpJumpNotTruthy:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			frame.ip += 2
+
+			if !isTruthy(vm.pop()) {
+				frame.ip = pos - 1
+			}
+		case code.OpJumpTruthy:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			frame.ip += 2
+
+			if isTruthy(vm.pop()) {
+				frame.ip = pos - 1
+			}
+		case code.OpSetGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+
+			vm.globals[globalIndex] = vm.pop()
+		case code.OpGetGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+
+			global := vm.globals[globalIndex]
+			if global == nil {
+				err = newError(object.NAME_ERROR, "identifier not found: %s", vm.globalNames[globalIndex])
+				break
+			}
+			err = vm.push(global)
+		case code.OpSetLocal:
+			localIndex := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+
+			frame.locals[localIndex] = vm.pop()
+		case code.OpGetLocal:
+			localIndex := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+
+			local := frame.locals[localIndex]
+			if local == nil {
+				err = newError(object.NAME_ERROR, "identifier not found: %s", frame.cl.Fn.LocalNames[localIndex])
+				break
+			}
+			err = vm.push(local)
+		case code.OpGetFree:
+			freeIndex := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+
+			free := *frame.cl.Free[freeIndex]
+			if free == nil {
+				err = newError(object.NAME_ERROR, "identifier not found: %s", frame.cl.Fn.Captures[freeIndex].Name)
+				break
+			}
+			err = vm.push(free)
+		case code.OpGetBuiltin:
+			builtinIndex := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+
+			builtin := vm.builtins.At(int(builtinIndex))
+			if builtin == nil {
+				err = newError(object.NAME_ERROR, "identifier not found: %s", vm.builtins.Name(int(builtinIndex)))
+				break
+			}
+			err = vm.push(builtin)
+		case code.OpAssignGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+
+			err = vm.assign(&vm.globals[globalIndex], vm.globalNames[globalIndex])
+		case code.OpAssignLocal:
+			localIndex := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+
+			err = vm.assign(&frame.locals[localIndex], frame.cl.Fn.LocalNames[localIndex])
+		case code.OpAssignFree:
+			freeIndex := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+
+			err = vm.assign(frame.cl.Free[freeIndex], frame.cl.Fn.Captures[freeIndex].Name)
+		case code.OpArray:
+			numElements := int(code.ReadUint16(ins[ip+1:]))
+			frame.ip += 2
+
+			elements := make([]object.Object, numElements)
+			copy(elements, vm.stack[vm.sp-numElements:vm.sp])
+			vm.sp = vm.sp - numElements
+
+			err = vm.pushResult(vm.allocate(&object.Array{Elements: elements}))
+		case code.OpHash:
+			numElements := int(code.ReadUint16(ins[ip+1:]))
+			frame.ip += 2
+
+			hash := vm.buildHash(vm.sp-numElements, vm.sp)
+			vm.sp = vm.sp - numElements
+
+			err = vm.pushResult(vm.allocate(hash))
+		case code.OpIndex:
+			index := vm.pop()
+			left := vm.pop()
+
+			err = vm.pushResult(executeIndexExpression(left, index))
+		case code.OpSetIndex:
+			value := vm.pop()
+			index := vm.pop()
+			left := vm.pop()
+
+			err = vm.pushResult(executeSetIndex(left, index, value))
+		case code.OpCall:
+			numArgs := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+
+			err = vm.executeCall(int(numArgs))
+		case code.OpTailCall:
+			numArgs := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+
+			err = vm.executeTailCall(int(numArgs))
+		case code.OpCallSpread:
+			numArgs := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+
+			var expanded int
+			expanded, err = vm.expandSpreadArguments(int(numArgs))
+			if err == nil {
+				err = vm.executeCall(expanded)
+			}
+		case code.OpSpread:
+			value := vm.pop()
+
+			array, ok := value.(*object.Array)
+			if !ok {
+				err = newError(object.TYPE_ERROR, "spread argument must be ARRAY, got %s", value.Type())
+				break
+			}
+
+			err = vm.push(&spread{elements: array.Elements})
+		case code.OpReturnValue:
+			returnValue := vm.pop()
+			err = vm.returnFromFrame(returnValue)
+		case code.OpReturn:
+			err = vm.returnFromFrame(NULL)
+		case code.OpClosure:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+
+			err = vm.pushClosure(int(constIndex))
+		case code.OpIter:
+			collection := vm.pop()
+
+			iterator, ok := object.NewIterator(collection)
+			if !ok {
+				err = newError(object.TYPE_ERROR, "cannot iterate over %s", collection.Type())
+				break
+			}
+			err = vm.push(iterator)
+		case code.OpIterNext:
+			exitIP := int(code.ReadUint16(ins[ip+1:]))
+			variables := code.ReadUint8(ins[ip+3:])
+			frame.ip += 3
+
+			iterator := vm.pop().(*object.Iterator)
+
+			key, value, ok := iterator.Next()
+			if !ok {
+				frame.ip = exitIP - 1
+				break
+			}
+
+			if variables == 2 {
+				err = vm.push(key)
+				if err == nil {
+					err = vm.push(value)
+				}
+			} else {
+				err = vm.push(iterator.Item(key, value))
+			}
+		case code.OpTry:
+			catchIP := int(code.ReadUint16(ins[ip+1:]))
+			frame.ip += 2
+
+			vm.handlers = append(vm.handlers, handler{framesIndex: vm.framesIndex, sp: vm.sp, catchIP: catchIP})
+		case code.OpEndTry:
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+		case code.OpCatch:
+			caught := vm.pop().(*object.Error)
+			err = vm.push(caught.CaughtValue())
+		case code.OpThrow:
+			thrown := vm.pop()
+			if thrownErr, ok := thrown.(*object.Error); ok {
+				err = thrownErr
+			} else {
+				err = object.NewThrownError(thrown)
+			}
+		default:
+			err = newError(object.ERROR, "unknown opcode: %d", op)
+		}
+
+		if err != nil {
+			if !err.Position.IsValid() {
+				err.Position = frame.Position(ip)
+			}
+
+			if len(vm.handlers) == 0 || !err.Catchable() {
+				vm.unwindFrames(err, 1)
+				return err
+			}
+
+			if err := vm.catch(err); err != nil {
+				return err
+			}
+		}
+	}
+
+	return vm.lastPopped
+}
+
+func (vm *VM) Call(fn object.Object, args []object.Object) object.Object {
+	return vm.CallContext(context.Background(), fn, args, object.Limits{})
+}
+
+func (vm *VM) CallContext(ctx context.Context, fn object.Object, args []object.Object, limits object.Limits) object.Object {
+	vm.limiter = object.NewLimiter(ctx, limits)
+
+	if err := vm.push(fn); err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		if err := vm.push(arg); err != nil {
+			return err
+		}
+	}
+
+	if err := vm.executeCall(len(args)); err != nil {
+		return err
+	}
+
+	if err, ok := vm.run().(*object.Error); ok {
+		return err
+	}
+
+	return vm.pop()
+}
+
+func (vm *VM) LastPoppedStackElem() object.Object {
+	return vm.lastPopped
+}
+
+func (vm *VM) currentFrame() *Frame {
+	return vm.frames[vm.framesIndex-1]
+}
+
+func (vm *VM) pushFrame(f *Frame) *object.Error {
+	if vm.framesIndex > vm.maxRecursionDepth {
+		return newError(object.RECURSION_ERROR, "maximum recursion depth exceeded")
+	}
+
+	if err := vm.limiter.Enter(); err != nil {
+		return err
+	}
+
+	if vm.framesIndex < len(vm.frames) {
+		vm.frames[vm.framesIndex] = f
+	} else {
+		vm.frames = append(vm.frames, f)
+	}
+	vm.framesIndex++
+
+	return nil
+}
+
+func (vm *VM) popFrame() *Frame {
+	vm.framesIndex--
+	frame := vm.frames[vm.framesIndex]
+	vm.frames[vm.framesIndex] = nil
+
+	if vm.framesIndex > 0 {
+		vm.limiter.Leave()
+	}
+
+	return frame
+}
+
+func (vm *VM) push(o object.Object) *object.Error {
+	if vm.sp >= len(vm.stack) {
+		if len(vm.stack) >= MaxStackSize {
+			return newError(object.ERROR, "stack overflow")
+		}
+
+		stack := make([]object.Object, len(vm.stack)*2)
+		copy(stack, vm.stack)
+		vm.stack = stack
+	}
+
+	vm.stack[vm.sp] = o
+	vm.sp++
+
+	return nil
+}
+
+func (vm *VM) pushResult(o object.Object) *object.Error {
+	if err, ok := o.(*object.Error); ok {
+		return err
+	}
+
+	return vm.push(o)
+}
+
+func (vm *VM) allocate(o object.Object) object.Object {
+	if err := vm.limiter.Allocate(o); err != nil {
+		return err
+	}
+
+	return o
+}
+
+func (vm *VM) pop() object.Object {
+	o := vm.stack[vm.sp-1]
+	vm.stack[vm.sp-1] = nil
+	vm.sp--
+
+	return o
+}
+
+func (vm *VM) assign(slot *object.Object, name string) *object.Error {
+	if *slot == nil {
+		return newError(object.NAME_ERROR, "identifier not found: %s", name)
+	}
+
+	*slot = vm.stack[vm.sp-1]
+
+	return nil
+}
+
+func (vm *VM) returnFromFrame(returnValue object.Object) *object.Error {
+	frame := vm.popFrame()
+
+	if vm.framesIndex == 0 {
+		vm.lastPopped = returnValue
+		return nil
+	}
+
+	vm.sp = frame.basePointer
+
+	for len(vm.handlers) > 0 && vm.handlers[len(vm.handlers)-1].framesIndex > vm.framesIndex {
+		vm.handlers = vm.handlers[:len(vm.handlers)-1]
+	}
+
+	return vm.push(returnValue)
+}
+
+func (vm *VM) catch(err *object.Error) *object.Error {
+	h := vm.handlers[len(vm.handlers)-1]
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+
+	vm.unwindFrames(err, h.framesIndex)
+
+	for i := h.sp; i < vm.sp; i++ {
+		vm.stack[i] = nil
+	}
+	vm.sp = h.sp
+	vm.currentFrame().ip = h.catchIP - 1
+
+	return vm.push(err)
+}
+
+func (vm *VM) unwindFrames(err *object.Error, framesIndex int) {
+	for vm.framesIndex > framesIndex {
+		frame := vm.popFrame()
+		caller := vm.currentFrame()
+
+		position := caller.CallPosition()
+		if frame.callSite.IsValid() {
+			position = frame.callSite
+		}
+
+		err.Stack = append(err.Stack, object.StackFrame{
+			Function:  frame.cl.Fn.Name,
+			Position:  position,
+			Arguments: frame.numArgs,
+			Caller:    frame.caller,
+			TailCalls: frame.tailCalls,
+		})
+	}
+}
+
+func (vm *VM) executeCall(numArgs int) *object.Error {
+	callee := vm.stack[vm.sp-1-numArgs]
+
+	switch callee := callee.(type) {
+	case *object.Closure:
+		return vm.callClosure(callee, numArgs)
+	case *object.Builtin:
+		return vm.callBuiltin(callee, numArgs)
+	default:
+		return newError(object.TYPE_ERROR, "not a function: %s", callee.Type())
+	}
+}
+
+func (vm *VM) executeTailCall(numArgs int) *object.Error {
+	cl, ok := vm.stack[vm.sp-1-numArgs].(*object.Closure)
+	if !ok || cl.Fn.CheckArity(numArgs) != nil || vm.framesIndex < 2 || vm.hasHandlerInCurrentFrame() {
+		return vm.executeCall(numArgs)
+	}
+
+	locals, err := vm.bindArguments(cl, numArgs)
+	if err != nil {
+		return err
+	}
+
+	current := vm.currentFrame()
+	for i := current.basePointer; i < vm.sp; i++ {
+		vm.stack[i] = nil
+	}
+	vm.sp = current.basePointer
+
+	frame := NewFrame(cl, locals, current.basePointer)
+	frame.numArgs = numArgs
+	frame.callSite = current.CallPosition()
+	frame.caller = current.cl.Fn.Name
+	frame.tailCalls = current.tailCalls + 1
+	vm.frames[vm.framesIndex-1] = frame
+
+	return nil
+}
+
+func (vm *VM) hasHandlerInCurrentFrame() bool {
+	return len(vm.handlers) > 0 && vm.handlers[len(vm.handlers)-1].framesIndex >= vm.framesIndex
+}
+
+func (vm *VM) callClosure(cl *object.Closure, numArgs int) *object.Error {
+	if err := cl.Fn.CheckArity(numArgs); err != nil {
+		return err
+	}
+
+	locals, err := vm.bindArguments(cl, numArgs)
+	if err != nil {
+		return err
+	}
+
+	basePointer := vm.sp - numArgs - 1
+	for i := basePointer; i < vm.sp; i++ {
+		vm.stack[i] = nil
+	}
+	vm.sp = basePointer
+
+	frame := NewFrame(cl, locals, basePointer)
+	frame.numArgs = numArgs
+
+	return vm.pushFrame(frame)
+}
+
+func (vm *VM) bindArguments(cl *object.Closure, numArgs int) ([]object.Object, *object.Error) {
+	locals := make([]object.Object, cl.Fn.NumLocals)
+	args := vm.stack[vm.sp-numArgs : vm.sp]
+
+	if numArgs <= cl.Fn.NumParameters {
+		copy(locals, args)
+	} else {
+		copy(locals, args[:cl.Fn.NumParameters])
+	}
+
+	if cl.Fn.Rest {
+		rest := []object.Object{}
+		if numArgs > cl.Fn.NumParameters {
+			rest = append(rest, args[cl.Fn.NumParameters:]...)
+		}
+
+		array := vm.allocate(&object.Array{Elements: rest})
+		if err, ok := array.(*object.Error); ok {
+			return nil, err
+		}
+
+		locals[cl.Fn.NumParameters] = array
+	}
+
+	return locals, nil
+}
+
+func (vm *VM) callBuiltin(builtin *object.Builtin, numArgs int) *object.Error {
+	args := make([]object.Object, numArgs)
+	copy(args, vm.stack[vm.sp-numArgs:vm.sp])
+
+	vm.sp = vm.sp - numArgs - 1
+
+	result := builtin.Fn(args...)
+	if result == nil {
+		result = NULL
+	}
+
+	return vm.pushResult(vm.allocate(result))
+}
+
+func (vm *VM) pushClosure(constIndex int) *object.Error {
+	constant := vm.constants[constIndex]
+	function, ok := constant.(*object.CompiledFunction)
+	if !ok {
+		return newError(object.TYPE_ERROR, "not a function: %+v", constant)
+	}
+
+	frame := vm.currentFrame()
+	free := make([]*object.Object, len(function.Captures))
+
+	for i, capture := range function.Captures {
+		if capture.Local {
+			free[i] = &frame.locals[capture.Index]
+		} else {
+			free[i] = frame.cl.Free[capture.Index]
+		}
+	}
+
+	return vm.pushResult(vm.allocate(&object.Closure{Fn: function, Free: free}))
+}
+
+func (vm *VM) buildHash(startIndex, endIndex int) object.Object {
+	pairs := make(map[object.HashKey]object.HashPair)
+
+	for i := startIndex; i < endIndex; i += 2 {
+		key := vm.stack[i]
+		value := vm.stack[i+1]
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError(object.TYPE_ERROR, "unusable as a hash key: %s", key.Type())
+		}
+
+		pairs[hashKey.HashKey()] = object.HashPair{Key: key, Value: value}
+	}
+
+	return &object.Hash{Pairs: pairs}
+}
+
+func newError(kind string, format string, a ...interface{}) *object.Error {
+	return &object.Error{Kind: kind, Message: fmt.Sprintf(format, a...)}
+}
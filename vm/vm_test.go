@@ -0,0 +1,140 @@
+package vm
+
+import (
+	"testing"
+
+	"monkeylang/ast"
+	"monkeylang/compiler"
+	"monkeylang/lexer"
+	"monkeylang/object"
+	"monkeylang/parser"
+)
+
+func TestDeepRecursion(t *testing.T) {
+	input := `
+	let countDown = fn(n) { if (n == 0) { 0 } else { countDown(n - 1) } };
+	countDown(100000);
+	`
+
+	testIntegerObject(t, testRun(t, input), 0)
+}
+
+func TestForwardReferencedGlobals(t *testing.T) {
+	input := `
+	let isEven = fn(n) { if (n == 0) { true } else { isOdd(n - 1) } };
+	let isOdd = fn(n) { if (n == 0) { false } else { isEven(n - 1) } };
+	isEven(10);
+	`
+
+	result, ok := testRun(t, input).(*object.Boolean)
+	if !ok || !result.Value {
+		t.Errorf("expected true, got=%+v", result)
+	}
+}
+
+func TestClosuresShareCapturedSlots(t *testing.T) {
+	input := `
+	let outer = fn() {
+		let x = 1;
+		let get = fn() { x };
+		let x = 2;
+		get();
+	};
+	outer();
+	`
+
+	testIntegerObject(t, testRun(t, input), 2)
+}
+
+func TestRecursiveLocalClosures(t *testing.T) {
+	input := `
+	let wrapper = fn() {
+		let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } };
+		fib(15);
+	};
+	wrapper();
+	`
+
+	testIntegerObject(t, testRun(t, input), 610)
+}
+
+func TestUndefinedLocalVariable(t *testing.T) {
+	input := `
+	let f = fn() {
+		if (false) { let x = 1; }
+		x;
+	};
+	f();
+	`
+
+	errObj, ok := testRun(t, input).(*object.Error)
+	if !ok {
+		t.Fatalf("expected error, got=%+v", errObj)
+	}
+
+	expected := "identifier not found: x"
+	if errObj.Message != expected {
+		t.Errorf("wrong error message. want=%q, got=%q", expected, errObj.Message)
+	}
+
+	if errObj.Position.String() != "4:3" {
+		t.Errorf("wrong error position. want=4:3, got=%s", errObj.Position)
+	}
+}
+
+func TestGlobalsStorePersistsAcrossRuns(t *testing.T) {
+	symbolTable := compiler.NewSymbolTable()
+	for i, def := range object.Builtins {
+		symbolTable.DefineBuiltin(i, def.Name)
+	}
+
+	constants := []object.Object{}
+	globals := make([]object.Object, GlobalsSize)
+
+	inputs := []string{"let a = 5;", "let b = fn() { a * 2 };", "b() + a"}
+
+	var result object.Object
+	for _, input := range inputs {
+		comp := compiler.NewWithState(symbolTable, constants)
+		if err := comp.Compile(parse(input)); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		bytecode := comp.Bytecode()
+		constants = bytecode.Constants
+
+		result = NewWithGlobalsStore(bytecode, globals).Run()
+	}
+
+	testIntegerObject(t, result, 15)
+}
+
+func testRun(t *testing.T, input string) object.Object {
+	t.Helper()
+
+	comp := compiler.New()
+	if err := comp.Compile(parse(input)); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	return New(comp.Bytecode()).Run()
+}
+
+func parse(input string) *ast.Program {
+	l := lexer.New(input)
+	p := parser.New(l)
+	return p.ParseProgram()
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) {
+	t.Helper()
+
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Fatalf("object is not Integer. got=%T (%+v)", obj, obj)
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. want=%d, got=%d", expected, result.Value)
+	}
+}